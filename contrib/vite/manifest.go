@@ -1,10 +1,16 @@
 package vite
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"io/fs"
+	"iter"
+	"maps"
+	"path"
+	"slices"
+	"strings"
 )
 
 type rawManifest = map[string]*ManifestEntry
@@ -34,6 +40,11 @@ type ManifestEntry struct {
 // It recursively walks the import graph to include all dependencies.
 // Returns (css, js, error) where css and js are ready-to-use HTML tags.
 func (m *Manifest) HTML(name string) ([]template.HTML, []template.HTML, error) {
+	return m.html(name, "")
+}
+
+// html is like HTML but appends attrs, e.g., a nonce attribute, to every tag.
+func (m *Manifest) html(name string, attrs string) ([]template.HTML, []template.HTML, error) {
 	seen := make(map[string]bool)
 
 	entry, ok := m.raw[name]
@@ -58,13 +69,13 @@ func (m *Manifest) HTML(name string) ([]template.HTML, []template.HTML, error) {
 		for _, link := range e.CSS {
 			//nolint:gosec
 			css = append(css, template.HTML(fmt.Sprintf(
-				`<link rel="stylesheet" href="%s" />`, link)))
+				`<link rel="stylesheet" href="%s"%s />`, link, attrs)))
 		}
 
 		for _, link := range e.Assets {
 			//nolint:gosec
 			js = append(js, template.HTML(fmt.Sprintf(
-				`<script type="module" src="%s"></script>`, link)))
+				`<script type="module" src="%s"%s></script>`, link, attrs)))
 		}
 
 		for _, i := range e.Imports {
@@ -77,6 +88,135 @@ func (m *Manifest) HTML(name string) ([]template.HTML, []template.HTML, error) {
 	return css, js, nil
 }
 
+// Entry returns the manifest entry with the given manifest key.
+//
+// The returned entry must not be modified.
+func (m *Manifest) Entry(name string) (*ManifestEntry, bool) {
+	entry, ok := m.raw[name]
+
+	return entry, ok
+}
+
+// Entries returns an iterator over all manifest entries keyed by manifest key,
+// in key order.
+//
+// The returned entries must not be modified.
+func (m *Manifest) Entries() iter.Seq2[string, *ManifestEntry] {
+	return func(yield func(string, *ManifestEntry) bool) {
+		for _, key := range slices.Sorted(maps.Keys(m.raw)) {
+			if !yield(key, m.raw[key]) {
+				return
+			}
+		}
+	}
+}
+
+// EntryByName finds an entry point by its source path (the "src" field) or its logical
+// name (the "name" field), returning the manifest key usable with HTML and the entry.
+//
+// Only entries with IsEntry set are considered, and a source path match takes precedence
+// over a name match. The returned error lists the available entries if none matches.
+func (m *Manifest) EntryByName(name string) (string, *ManifestEntry, error) {
+	keys := slices.Sorted(maps.Keys(m.raw))
+
+	var byName []string
+
+	for _, key := range keys {
+		entry := m.raw[key]
+		if !entry.IsEntry {
+			continue
+		}
+
+		if entry.Source == name {
+			return key, entry, nil
+		}
+
+		if entry.Name == name {
+			byName = append(byName, key)
+		}
+	}
+
+	switch len(byName) {
+	case 1:
+		return byName[0], m.raw[byName[0]], nil
+	case 0:
+		available := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			if entry := m.raw[key]; entry.IsEntry {
+				available = append(available, fmt.Sprintf("%s (name: %s)", cmp.Or(entry.Source, key), entry.Name))
+			}
+		}
+
+		return "", nil, fmt.Errorf("inertia: entry %s not found in manifest, available entries: %s",
+			name, strings.Join(available, ", "))
+	default:
+		return "", nil, fmt.Errorf("inertia: entry name %s is ambiguous, matching entries: %s",
+			name, strings.Join(byName, ", "))
+	}
+}
+
+const (
+	// legacySuffix is appended by @vitejs/plugin-legacy to the names of legacy entries,
+	// e.g., "src/main.ts" becomes "src/main-legacy.ts".
+	legacySuffix = "-legacy"
+
+	// legacyPolyfillsName is the manifest key of the legacy polyfills chunk,
+	// including the SystemJS loader.
+	legacyPolyfillsName = "vite/legacy-polyfills" + legacySuffix
+
+	legacyEntryID = "vite-legacy-entry"
+)
+
+// HTMLWithLegacy is like HTML, but also supports browsers without ES modules
+// through the entries emitted by @vitejs/plugin-legacy.
+//
+// The returned js contains, in order, the modern entry script with type="module",
+// the nomodule legacy polyfills (SystemJS) and the nomodule legacy entry,
+// so that browsers execute either the modern or the legacy bundle.
+func (m *Manifest) HTMLWithLegacy(name string) ([]template.HTML, []template.HTML, error) {
+	css, js, err := m.HTML(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry := m.raw[name]
+
+	//nolint:gosec
+	js = append(js, template.HTML(fmt.Sprintf(
+		`<script type="module" src="%s"></script>`, entry.File)))
+
+	legacy, ok := m.raw[legacyEntryName(name)]
+	if !ok {
+		return nil, nil, fmt.Errorf("inertia: legacy entry for %s not found in manifest", name)
+	}
+
+	polyfills, ok := m.raw[legacyPolyfillsName]
+	if !ok {
+		return nil, nil, fmt.Errorf("inertia: entry %s not found in manifest", legacyPolyfillsName)
+	}
+
+	//nolint:gosec
+	js = append(js,
+		template.HTML(fmt.Sprintf(
+			`<script nomodule crossorigin id="vite-legacy-polyfill" src="%s"></script>`, polyfills.File)),
+		template.HTML(fmt.Sprintf(
+			`<script nomodule crossorigin id="%[1]s" data-src="%[2]s">`+
+				`System.import(document.getElementById('%[1]s').getAttribute('data-src'))</script>`,
+			legacyEntryID, legacy.File)),
+	)
+
+	return css, js, nil
+}
+
+// legacyEntryName returns the manifest key of the legacy entry for name,
+// e.g., "src/main-legacy.ts" for "src/main.ts".
+func legacyEntryName(name string) string {
+	ext := path.Ext(name)
+
+	return strings.TrimSuffix(name, ext) + legacySuffix + ext
+}
+
 // ParseManifest parses a Vite build manifest from JSON bytes.
 //
 // The manifest maps entry point names to their compiled assets and dependencies.
@@ -0,0 +1,69 @@
+package vite
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"go.inout.gg/foundations/debug"
+)
+
+// ManifestProvider provides a parsed Vite manifest read from a file system.
+//
+// In production (build tag: -tags=production) the manifest is parsed once and cached.
+// In development the manifest is re-read whenever its modification time changes,
+// so that rebuilds, e.g., with vite build --watch, are picked up without restarting
+// the server. See Config.ManifestProvider.
+type ManifestProvider struct {
+	fsys     fs.FS
+	manifest *Manifest
+	modTime  time.Time
+	path     string
+	mu       sync.Mutex
+	reload   bool
+}
+
+// NewManifestProvider creates a ManifestProvider reading the manifest at path from fsys.
+func NewManifestProvider(fsys fs.FS, path string) *ManifestProvider {
+	debug.Assert(fsys != nil, "expected fsys to be defined")
+	debug.Assert(path != "", "expected path to be defined")
+
+	//nolint:exhaustruct
+	return &ManifestProvider{fsys: fsys, path: path, reload: reloadManifest}
+}
+
+// Manifest returns the parsed manifest, parsing it on first use
+// and, in development, whenever the file has changed.
+func (p *ManifestProvider) Manifest() (*Manifest, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.manifest != nil && !p.reload {
+		return p.manifest, nil
+	}
+
+	var modTime time.Time
+
+	if p.reload {
+		info, err := fs.Stat(p.fsys, p.path)
+		if err != nil {
+			return nil, fmt.Errorf("inertia: failed to stat manifest file: %w", err)
+		}
+
+		modTime = info.ModTime()
+		if p.manifest != nil && modTime.Equal(p.modTime) {
+			return p.manifest, nil
+		}
+	}
+
+	manifest, err := ParseManifestFromFS(p.fsys, p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	p.manifest = manifest
+	p.modTime = modTime
+
+	return manifest, nil
+}
@@ -0,0 +1,97 @@
+package vite
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestProvider(t *testing.T) {
+	t.Parallel()
+
+	newFS := func(file string, modTime time.Time) fstest.MapFS {
+		return fstest.MapFS{"manifest.json": &fstest.MapFile{
+			Data:    []byte(`{"main.js": {"file": "` + file + `", "isEntry": true}}`),
+			ModTime: modTime,
+		}}
+	}
+
+	t.Run("returns cached manifest when unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		fsys := newFS("assets/main-1.js", time.Unix(1, 0))
+		p := NewManifestProvider(fsys, "manifest.json")
+		p.reload = true
+
+		first, err := p.Manifest()
+		require.NoError(t, err)
+
+		// act
+		second, err := p.Manifest()
+
+		// assert
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+	})
+
+	t.Run("reloads manifest when modtime changes", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		fsys := newFS("assets/main-1.js", time.Unix(1, 0))
+		p := NewManifestProvider(fsys, "manifest.json")
+		p.reload = true
+
+		first, err := p.Manifest()
+		require.NoError(t, err)
+
+		fsys["manifest.json"] = newFS("assets/main-2.js", time.Unix(2, 0))["manifest.json"]
+
+		// act
+		second, err := p.Manifest()
+
+		// assert
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.Equal(t, "assets/main-2.js", second.raw["main.js"].File)
+	})
+
+	t.Run("parses once without reload", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		fsys := newFS("assets/main-1.js", time.Unix(1, 0))
+		p := NewManifestProvider(fsys, "manifest.json")
+		p.reload = false
+
+		first, err := p.Manifest()
+		require.NoError(t, err)
+
+		fsys["manifest.json"] = newFS("assets/main-2.js", time.Unix(2, 0))["manifest.json"]
+
+		// act
+		second, err := p.Manifest()
+
+		// assert
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+		assert.Equal(t, "assets/main-1.js", second.raw["main.js"].File)
+	})
+
+	t.Run("missing manifest returns error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		p := NewManifestProvider(fstest.MapFS{}, "manifest.json")
+
+		// act
+		_, err := p.Manifest()
+
+		// assert
+		require.Error(t, err)
+	})
+}
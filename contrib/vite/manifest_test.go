@@ -177,3 +177,173 @@ func TestManifestHTML(t *testing.T) {
 		assert.Contains(t, err.Error(), "nonexistent.js")
 	})
 }
+
+func TestManifestHTMLWithLegacy(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	manifest, err := ParseManifestFromFS(os.DirFS("testdata"), "manifest-legacy.json")
+	require.NoError(t, err)
+
+	t.Run("emits modern and legacy scripts in order", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		css, js, err := manifest.HTMLWithLegacy("src/main.ts")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []template.HTML{`<link rel="stylesheet" href="assets/main-Dq9dF1eQ.css" />`}, css)
+		assert.Equal(t, []template.HTML{
+			`<script type="module" src="assets/main-C3fPq6_0.js"></script>`,
+			`<script nomodule crossorigin id="vite-legacy-polyfill" src="assets/polyfills-legacy-Cw2aX8Zt.js"></script>`,
+			`<script nomodule crossorigin id="vite-legacy-entry" data-src="assets/main-legacy-B9bJc2Yk.js">` +
+				`System.import(document.getElementById('vite-legacy-entry').getAttribute('data-src'))</script>`,
+		}, js)
+	})
+
+	t.Run("missing legacy entry returns error", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, _, err := manifest.HTMLWithLegacy("src/main-legacy.ts")
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "legacy entry")
+	})
+
+	t.Run("entry not found returns error", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, _, err := manifest.HTMLWithLegacy("nonexistent.ts")
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent.ts")
+	})
+}
+
+func TestManifestEntryByName(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	manifest, err := ParseManifestFromFS(os.DirFS("testdata"), "manifest.json")
+	require.NoError(t, err)
+
+	t.Run("lookup by src", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		key, entry, err := manifest.EntryByName("views/foo.js")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "views/foo.js", key)
+		assert.Equal(t, "assets/foo-BRBmoGS9.js", entry.File)
+	})
+
+	t.Run("lookup by name", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		key, entry, err := manifest.EntryByName("bar")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "views/bar.js", key)
+		assert.Equal(t, "assets/bar-gkvgaI9m.js", entry.File)
+
+		css, _, err := manifest.HTML(key)
+		require.NoError(t, err)
+		assert.NotEmpty(t, css)
+	})
+
+	t.Run("ignores non-entry chunks", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, _, err := manifest.EntryByName("shared")
+
+		// assert
+		require.Error(t, err)
+	})
+
+	t.Run("not found lists available entries", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, _, err := manifest.EntryByName("nonexistent")
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent")
+		assert.Contains(t, err.Error(), "views/bar.js (name: bar), views/foo.js (name: foo)")
+	})
+}
+
+func TestManifestEntries(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	manifest, err := ParseManifestFromFS(os.DirFS("testdata"), "manifest.json")
+	require.NoError(t, err)
+
+	t.Run("Entry looks up by key", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		entry, ok := manifest.Entry("views/foo.js")
+
+		// assert
+		require.True(t, ok)
+		assert.Equal(t, "assets/foo-BRBmoGS9.js", entry.File)
+		assert.Equal(t, []string{"assets/foo-5UjPuW-k.css"}, entry.CSS)
+	})
+
+	t.Run("Entry reports missing key", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		entry, ok := manifest.Entry("nonexistent.js")
+
+		// assert
+		assert.False(t, ok)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("Entries iterates all entries in key order", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		var keys, files []string
+
+		for key, entry := range manifest.Entries() {
+			keys = append(keys, key)
+			files = append(files, entry.File)
+		}
+
+		// assert
+		assert.Equal(t, []string{
+			"_shared-B7PI925R.js", "_shared-ChJ_j-JJ.css", "baz.js", "views/bar.js", "views/foo.js",
+		}, keys)
+		assert.Equal(t, "assets/baz-B2H3sXNv.js", files[2])
+	})
+
+	t.Run("Entries stops early", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		n := 0
+
+		for range manifest.Entries() {
+			n++
+
+			break
+		}
+
+		// assert
+		assert.Equal(t, 1, n)
+	})
+}
@@ -10,17 +10,27 @@ import (
 	"go.inout.gg/foundations/must"
 )
 
+// reloadManifest makes ManifestProvider re-read the manifest when it changes.
+const reloadManifest = true
+
 // parseTemplate parses a template from a string.
-func parseTemplate(name string, content string) *template.Template {
-	return template.Must(template.New(name).Parse(content))
+func parseTemplate(name string, content string, funcs template.FuncMap) *template.Template {
+	return template.Must(template.New(name).Funcs(funcs).Parse(content))
 }
 
 // newTemplate creates a new template with Vite support.
+//
+// Without ViteAddress, assets are resolved from the manifest instead of
+// the dev server, e.g., built with vite build --watch.
 func newTemplate(cfg *Config) *template.Template {
+	if cfg.ViteAddress == "" {
+		return newManifestTemplate(cfg)
+	}
+
 	viteClientURL := must.Must(url.JoinPath(cfg.ViteAddress, "@vite/client"))
 	viteReactRefreshURL := must.Must(url.JoinPath(cfg.ViteAddress, "@react-refresh"))
-	viteClientTemplate := fmt.Sprintf(`<script type="module" src="%s"></script>`, viteClientURL)
-	viteReactRefreshTemplate := fmt.Sprintf(`<script type="module">
+	viteClientTemplate := fmt.Sprintf(`<script type="module" src="%s"{{ viteNonce . }}></script>`, viteClientURL)
+	viteReactRefreshTemplate := fmt.Sprintf(`<script type="module"{{ viteNonce . }}>
   import RefreshRuntime from "%s";
   RefreshRuntime.injectIntoGlobalHook(window);
   window.$RefreshReg$ = () => {};
@@ -28,20 +38,22 @@ func newTemplate(cfg *Config) *template.Template {
   window.__vite_plugin_react_preamble_installed__ = true;
 </script>`, viteReactRefreshURL)
 
-	tpl := template.New(cfg.TemplateName).Funcs(template.FuncMap{
-		"viteResource": func(path string) template.HTML {
+	funcs := template.FuncMap{
+		"viteResource": func(path string, data ...any) template.HTML {
 			url := must.Must(url.JoinPath(cfg.ViteAddress, path))
 
 			//nolint:gosec
-			return template.HTML(fmt.Sprintf(`<script type="module" src="%s"></script>`, url))
+			return template.HTML(fmt.Sprintf(`<script type="module" src="%s"%s></script>`, url, nonceAttr(data...)))
 		},
-	})
+		"viteNonce": func(data any) template.HTMLAttr { return nonceAttr(data) },
+	}
+	tpl := template.New(cfg.TemplateName).Funcs(funcs)
 
-	template.Must(tpl.AddParseTree("viteClient", parseTemplate("inertia/viteClient", viteClientTemplate).Tree))
+	template.Must(tpl.AddParseTree("viteClient", parseTemplate("inertia/viteClient", viteClientTemplate, funcs).Tree))
 	template.Must(
 		tpl.AddParseTree(
 			"viteReactRefresh",
-			parseTemplate("inertia/viteReactRefresh", viteReactRefreshTemplate).Tree,
+			parseTemplate("inertia/viteReactRefresh", viteReactRefreshTemplate, funcs).Tree,
 		),
 	)
 
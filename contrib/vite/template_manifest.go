@@ -0,0 +1,53 @@
+package vite
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var noopTemplate = template.Must(template.New("noop").Parse(""))
+
+// newManifestTemplate creates a new template resolving assets from the manifest
+// of the ManifestProvider, without the Vite dev server.
+func newManifestTemplate(c *Config) *template.Template {
+	t := template.New(c.TemplateName)
+	t.Funcs(template.FuncMap{
+		"viteResource": func(path string, data ...any) (template.HTML, error) {
+			if c.ManifestProvider == nil {
+				return template.HTML(""), nil
+			}
+
+			manifest, err := c.ManifestProvider.Manifest()
+			if err != nil {
+				return "", err
+			}
+
+			attrs := string(nonceAttr(data...))
+
+			css, js, err := manifest.html(path, attrs)
+			if err != nil {
+				return "", err
+			}
+
+			var b strings.Builder
+
+			for _, tag := range css {
+				b.WriteString(string(tag))
+			}
+
+			for _, tag := range js {
+				b.WriteString(string(tag))
+			}
+
+			fmt.Fprintf(&b, `<script type="module" src="%s"%s></script>`, manifest.raw[path].File, attrs)
+
+			return template.HTML(b.String()), nil
+		},
+	})
+
+	t.AddParseTree("viteClient", noopTemplate.Tree)
+	t.AddParseTree("viteReactRefresh", noopTemplate.Tree)
+
+	return t
+}
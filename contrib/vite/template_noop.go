@@ -4,18 +4,9 @@ package vite
 
 import "html/template"
 
-var noopTemplate = template.Must(template.New("noop").Parse(""))
+// reloadManifest makes ManifestProvider re-read the manifest when it changes.
+const reloadManifest = false
 
 func newTemplate(c *Config) *template.Template {
-	t := template.New(c.TemplateName)
-	t.Funcs(template.FuncMap{
-		"viteResource": func(path string) template.HTML {
-			return template.HTML("")
-		},
-	})
-
-	t.AddParseTree("viteClient", noopTemplate.Tree)
-	t.AddParseTree("viteReactRefresh", noopTemplate.Tree)
-
-	return t
+	return newManifestTemplate(c)
 }
@@ -0,0 +1,124 @@
+//go:build !production
+
+package vite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia"
+)
+
+func TestTemplateNonce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected func(nonce string) []string
+	}{
+		{
+			name:    "template data sets nonce on all helpers",
+			content: `{{ template "viteClient" . }}{{ template "viteReactRefresh" . }}{{ viteResource "src/main.tsx" . }}`,
+			expected: func(nonce string) []string {
+				return []string{
+					`<script type="module" src="http://localhost:5173/@vite/client" nonce="` + nonce + `"></script>`,
+					`<script type="module" nonce="` + nonce + `">`,
+					`<script type="module" src="http://localhost:5173/src/main.tsx" nonce="` + nonce + `"></script>`,
+				}
+			},
+		},
+		{
+			name:    "nonce string sets nonce on resource",
+			content: `{{ viteResource "src/main.tsx" .Nonce }}`,
+			expected: func(nonce string) []string {
+				return []string{
+					`<script type="module" src="http://localhost:5173/src/main.tsx" nonce="` + nonce + `"></script>`,
+				}
+			},
+		},
+		{
+			name:    "helpers without data render no nonce",
+			content: `{{ template "viteClient" }}{{ viteResource "src/main.tsx" }}`,
+			expected: func(string) []string {
+				return []string{
+					`<script type="module" src="http://localhost:5173/@vite/client"></script>`,
+					`<script type="module" src="http://localhost:5173/src/main.tsx"></script>`,
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := inertia.New(Must(tt.content+`{{ .InertiaBody }}`, nil), nil)
+
+			var nonce string
+
+			handler := inertia.NonceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nonce = inertia.NonceFromContext(r.Context())
+				_ = renderer.Render(w, r, "Index", inertia.NewRenderContext())
+			}))
+
+			w := httptest.NewRecorder()
+
+			// act
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// assert
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NotEmpty(t, nonce)
+
+			for _, tag := range tt.expected(nonce) {
+				assert.Contains(t, w.Body.String(), tag)
+			}
+		})
+	}
+}
+
+func TestTemplateManifestProvider(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	fsys := fstest.MapFS{"manifest.json": &fstest.MapFile{
+		Data:    []byte(`{"main.js": {"file": "assets/main-1.js", "isEntry": true}}`),
+		ModTime: time.Unix(1, 0),
+	}}
+
+	//nolint:exhaustruct
+	tpl := Must(`{{ template "viteClient" }}{{ viteResource "main.js" }}`, &Config{
+		ManifestProvider: NewManifestProvider(fsys, "manifest.json"),
+	})
+
+	render := func() string {
+		var b strings.Builder
+
+		require.NoError(t, tpl.Execute(&b, nil))
+
+		return b.String()
+	}
+
+	// act
+	first := render()
+
+	fsys["manifest.json"] = &fstest.MapFile{
+		Data:    []byte(`{"main.js": {"file": "assets/main-2.js", "isEntry": true}}`),
+		ModTime: time.Unix(2, 0),
+	}
+
+	second := render()
+
+	// assert: assets are resolved from the manifest, reloaded when it changes
+	assert.Equal(t, `<script type="module" src="assets/main-1.js"></script>`, first)
+	assert.Equal(t, `<script type="module" src="assets/main-2.js"></script>`, second)
+}
@@ -9,21 +9,36 @@ import (
 
 	"go.inout.gg/foundations/debug"
 	"go.inout.gg/foundations/must"
+
+	"go.segfaultmedaddy.com/inertia"
 )
 
 const DefaultViteAddress = "http://localhost:5173"
 
 type Config struct {
+	// ManifestProvider provides the manifest used to resolve assets in production.
+	// If nil, viteResource renders nothing in production.
+	//
+	// In development, it is used instead of the Vite dev server if ViteAddress
+	// is empty, e.g., to serve assets built with vite build --watch.
+	ManifestProvider *ManifestProvider
+
 	Manifest     Manifest
 	TemplateName string
-	ViteAddress  string
+
+	// ViteAddress is the address of the Vite dev server used in development.
+	// Defaults to DefaultViteAddress, unless ManifestProvider is set.
+	ViteAddress string
 }
 
 func (c *Config) defaults() {
-	c.ViteAddress = cmp.Or(c.ViteAddress, DefaultViteAddress)
+	if c.ManifestProvider == nil {
+		c.ViteAddress = cmp.Or(c.ViteAddress, DefaultViteAddress)
+	}
+
 	c.TemplateName = cmp.Or(c.TemplateName, "inertia")
 
-	debug.Assert(c.ViteAddress != "", "vite address must be set")
+	debug.Assert(c.ViteAddress != "" || c.ManifestProvider != nil, "vite address or manifest provider must be set")
 	debug.Assert(c.TemplateName != "", "template name must be set")
 }
 
@@ -34,7 +49,15 @@ func (c *Config) defaults() {
 //   - {{template "viteClient"}}: Vite development client (dev only, blank in production)
 //   - {{template "viteReactRefresh"}}: React Fast Refresh support (dev only, blank in production)
 //
-// In development mode, assets are loaded from the Vite dev server at ViteAddress.
+// To set the Content-Security-Policy nonce (see inertia.NonceMiddleware) on the
+// emitted tags, pass the template data to them:
+//
+//	{{viteResource "path/to/file.js" .}}
+//	{{template "viteClient" .}}
+//	{{template "viteReactRefresh" .}}
+//
+// In development mode, assets are loaded from the Vite dev server at ViteAddress,
+// or from the manifest of ManifestProvider if ViteAddress is empty.
 // In production mode (build tag: -tags=production), assets are resolved from the manifest.
 func NewTemplate(content string, config *Config) (*template.Template, error) {
 	if config == nil {
@@ -74,3 +97,26 @@ func FromFS(fsys fs.FS, path string, cfg *Config) (*template.Template, error) {
 
 	return t, nil
 }
+
+// nonceAttr returns the nonce attribute for data, which is either
+// the template data (*inertia.TemplateData) or the nonce itself.
+// It returns an empty string if there is no nonce.
+func nonceAttr(data ...any) template.HTMLAttr {
+	var nonce string
+
+	for _, d := range data {
+		switch d := d.(type) {
+		case *inertia.TemplateData:
+			nonce = d.Nonce
+		case string:
+			nonce = d
+		}
+	}
+
+	if nonce == "" {
+		return ""
+	}
+
+	//nolint:gosec
+	return template.HTMLAttr(` nonce="` + template.HTMLEscapeString(nonce) + `"`)
+}
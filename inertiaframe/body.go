@@ -0,0 +1,145 @@
+package inertiaframe
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-playground/form/v4"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
+)
+
+const headerContentEncoding = "Content-Encoding"
+
+// DefaultMaxBodyBytes is the default maximum size in bytes of a request body.
+const DefaultMaxBodyBytes = 10 << 20 // 10 MiB
+
+// DefaultMaxDecompressedBodySize is the default maximum size in bytes
+// of a decompressed request body.
+const DefaultMaxDecompressedBodySize = 10 << 20 // 10 MiB
+
+// ErrBodyTooLarge is returned when a decompressed request body exceeds
+// MountOpts.MaxDecompressedBodySize. DefaultErrorHandler responds to it
+// with 413 Request Entity Too Large.
+var ErrBodyTooLarge = errors.New("inertiaframe: decompressed request body too large")
+
+// decompressBody replaces the body of a gzip-encoded request r with
+// a reader decompressing it, limited to limit bytes. A negative limit
+// disables the limit.
+//
+// Requests with other or no content encoding are left as is.
+func decompressBody(r *http.Request, limit int64) error {
+	encoding := strings.TrimSpace(r.Header.Get(headerContentEncoding))
+	if !strings.EqualFold(encoding, "gzip") && !strings.EqualFold(encoding, "x-gzip") {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return fmt.Errorf("inertiaframe: failed to read gzip request body: %w", err)
+	}
+
+	d("decompressing gzip request body")
+
+	r.Body = &gzipBody{zr: zr, body: r.Body, remaining: limit, unlimited: limit < 0}
+	r.ContentLength = -1
+	r.Header.Del(headerContentEncoding)
+
+	return nil
+}
+
+// gzipBody decompresses a request body, failing with ErrBodyTooLarge
+// once more than the remaining bytes are read, unless it is unlimited.
+type gzipBody struct {
+	zr        *gzip.Reader
+	body      io.ReadCloser
+	remaining int64
+	unlimited bool
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.unlimited {
+		return b.zr.Read(p) //nolint:wrapcheck
+	}
+
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	// Read one byte past the limit to tell a body of exactly the limit size
+	// from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.zr.Read(p)
+
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n - 1, ErrBodyTooLarge
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+func (b *gzipBody) Close() error {
+	return errors.Join(b.zr.Close(), b.body.Close())
+}
+
+// decodeBody decodes the JSON or form body of r into msg.
+//
+// Requests without a body may omit the Content-Type header, leaving msg as is.
+// Other content types fail with ErrUnsupportedMediaType.
+func decodeBody(r *http.Request, msg any, formDecoder *form.Decoder, jsonUnmarshalOptions []json.Options) error {
+	contentType := r.Header.Get(inertiaheader.HeaderContentType)
+	if contentType == "" {
+		// Requests without a body, e.g., DELETE, may omit the Content-Type.
+		if !hasBody(r) {
+			d("received request without body")
+
+			return nil
+		}
+
+		return fmt.Errorf("%w: missing Content-Type header", ErrUnsupportedMediaType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("inertiaframe: failed to parse Content-Type header: %w", err)
+	}
+
+	// Inertia accepts only JSON or multipart/form-data.
+	switch {
+	case isJSONMediaType(mediaType):
+		d("received JSON request")
+
+		if err := json.UnmarshalRead(r.Body, msg, jsonUnmarshalOptions...); err != nil {
+			return fmt.Errorf("inertiaframe: failed to decode request: %w", err)
+		}
+	case mediaType == mediaTypeForm, mediaType == mediaTypeMultipart:
+		d("received form request")
+
+		if err := r.ParseForm(); err != nil {
+			return fmt.Errorf("inertiaframe: failed to parse form data: %w", err)
+		}
+
+		if err := formDecoder.Decode(msg, r.Form); err != nil {
+			return fmt.Errorf("inertiaframe: failed to decode form data: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	return nil
+}
+
+// hasBody reports whether r may have a body.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
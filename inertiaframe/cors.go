@@ -0,0 +1,183 @@
+package inertiaframe
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.inout.gg/foundations/debug"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
+)
+
+const (
+	headerOrigin                        = "Origin"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+)
+
+// DefaultCORSAllowedMethods are the methods allowed by default.
+//
+//nolint:gochecknoglobals
+var DefaultCORSAllowedMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// DefaultCORSAllowedHeaders are the request headers allowed by default,
+// covering the headers sent by the Inertia client.
+//
+//nolint:gochecknoglobals
+var DefaultCORSAllowedHeaders = []string{
+	inertiaheader.HeaderContentType,
+	"X-Requested-With",
+	inertiaheader.HeaderXInertia,
+	inertiaheader.HeaderXInertiaVersion,
+	inertiaheader.HeaderXInertiaPartialData,
+	inertiaheader.HeaderXInertiaPartialExcept,
+	inertiaheader.HeaderXInertiaPartialComponent,
+	inertiaheader.HeaderXInertiaReset,
+	inertiaheader.HeaderXInertiaErrorBag,
+}
+
+// DefaultCORSExposedHeaders are the response headers exposed by default,
+// covering the headers read by the Inertia client.
+//
+//nolint:gochecknoglobals
+var DefaultCORSExposedHeaders = []string{
+	inertiaheader.HeaderXInertia,
+	inertiaheader.HeaderXInertiaLocation,
+}
+
+// CORSConfig configures cross-origin resource sharing for a mounted endpoint.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests,
+	// e.g., "https://app.example.com". The "*" origin allows any origin,
+	// it can't be used with AllowCredentials.
+	AllowedOrigins []string
+
+	// AllowedMethods lists the methods allowed in cross-origin requests.
+	// Defaults to DefaultCORSAllowedMethods.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed in cross-origin requests.
+	// Defaults to DefaultCORSAllowedHeaders.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers exposed to the client.
+	// Defaults to DefaultCORSExposedHeaders.
+	ExposedHeaders []string
+
+	// AllowCredentials allows cross-origin requests to include cookies,
+	// e.g., the session cookie flashing validation errors.
+	//
+	// The allowed origins must be listed explicitly, as allowing credentialed
+	// requests from any origin would let every site act on behalf of the user.
+	AllowCredentials bool
+
+	// MaxAge is the number of seconds the preflight response may be cached.
+	// If zero, the header is omitted.
+	MaxAge int
+}
+
+// cors applies a CORSConfig to the endpoints mounted on a single path.
+type cors struct {
+	config CORSConfig
+}
+
+func newCORS(config *CORSConfig) *cors {
+	if config.AllowCredentials && slices.Contains(config.AllowedOrigins, "*") {
+		panic(`inertiaframe: CORS credentials can't be allowed for the "*" origin`)
+	}
+
+	c := &cors{config: *config}
+	if len(c.config.AllowedMethods) == 0 {
+		c.config.AllowedMethods = DefaultCORSAllowedMethods
+	}
+
+	if len(c.config.AllowedHeaders) == 0 {
+		c.config.AllowedHeaders = DefaultCORSAllowedHeaders
+	}
+
+	if len(c.config.ExposedHeaders) == 0 {
+		c.config.ExposedHeaders = DefaultCORSExposedHeaders
+	}
+
+	return c
+}
+
+// allowOrigin sets the Access-Control-Allow-Origin header if the request origin is allowed.
+// It reports whether the origin is allowed.
+func (c *cors) allowOrigin(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	h.Add(inertiaheader.HeaderVary, headerOrigin)
+
+	origin := r.Header.Get(headerOrigin)
+	if origin == "" {
+		return false
+	}
+
+	switch {
+	case slices.Contains(c.config.AllowedOrigins, origin):
+		h.Set(headerAccessControlAllowOrigin, origin)
+	case slices.Contains(c.config.AllowedOrigins, "*"):
+		h.Set(headerAccessControlAllowOrigin, "*")
+	default:
+		d("rejected cross-origin request from %s", origin)
+
+		return false
+	}
+
+	if c.config.AllowCredentials {
+		h.Set(headerAccessControlAllowCredentials, "true")
+	}
+
+	return true
+}
+
+// ServeHTTP responds to preflight requests.
+func (c *cors) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.allowOrigin(w, r) && r.Header.Get(headerAccessControlRequestMethod) != "" {
+		h := w.Header()
+		h.Set(headerAccessControlAllowMethods, strings.Join(c.config.AllowedMethods, ", "))
+		h.Set(headerAccessControlAllowHeaders, strings.Join(c.config.AllowedHeaders, ", "))
+
+		if c.config.MaxAge > 0 {
+			h.Set(headerAccessControlMaxAge, strconv.Itoa(c.config.MaxAge))
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handler adds the CORS headers to the responses of next.
+func (c *cors) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.allowOrigin(w, r) {
+			w.Header().Set(headerAccessControlExposeHeaders, strings.Join(c.config.ExposedHeaders, ", "))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MountCORS registers a handler responding to preflight OPTIONS requests
+// to the path on mux with config.
+//
+// It is registered once per path, while the endpoints mounted on the path add
+// the CORS headers to their responses with MountOpts.CORS.
+func MountCORS(mux Mux, path string, config *CORSConfig) {
+	debug.Assert(config != nil, "CORS config must not be nil")
+
+	mux.Handle(http.MethodOptions+" "+path, newCORS(config))
+}
@@ -5,19 +5,26 @@
 package inertiaframe
 
 import (
+	"bytes"
 	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"mime"
 	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-json-experiment/json"
 	"github.com/go-playground/form/v4"
 	"go.inout.gg/foundations/debug"
 	"go.inout.gg/foundations/http/httphandler"
 	"go.inout.gg/foundations/http/httpmiddleware"
-	"go.inout.gg/foundations/must"
 
 	"go.segfaultmedaddy.com/inertia"
 	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
@@ -30,6 +37,38 @@ var DefaultFormDecoder = form.NewDecoder() //nolint:gochecknoglobals
 
 var ErrEmptyResponse = errors.New("inertiaframe: empty response")
 
+// ErrUnsupportedMediaType is returned when a request body has a Content-Type
+// other than JSON or form data. DefaultErrorHandler responds to it
+// with 415 Unsupported Media Type.
+var ErrUnsupportedMediaType = errors.New("inertiaframe: unsupported media type")
+
+// HTTPError is an error carrying the HTTP status code of the response,
+// see StatusError.
+type HTTPError struct {
+	Err    error
+	Status int
+}
+
+// StatusError wraps err with the HTTP status code of the response, e.g.,
+// http.StatusNotFound, for an endpoint to signal the intended status.
+//
+// DefaultErrorHandler responds to it with the status code. For Inertia GET
+// visits it forces a full page visit to the same URL instead, so that the
+// browser displays the error page rather than the Inertia error modal.
+func StatusError(status int, err error) error {
+	return &HTTPError{Err: err, Status: status}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error { return e.Err }
+
 type (
 	Middleware     = httpmiddleware.Middleware
 	MiddlewareFunc = httpmiddleware.MiddlewareFunc
@@ -41,37 +80,44 @@ var (
 	_ RawResponseWriter = (*redirectMessage)(nil)
 	_ RawResponseWriter = (*redirectBackMessage)(nil)
 	_ RawResponseWriter = (*externalRedirectMessage)(nil)
+	_ RawResponseWriter = (*downloadMessage)(nil)
+	_ RawResponseWriter = (*fileResp)(nil)
+	_ RawResponseWriter = (*fsFileResp)(nil)
 	_ Response          = (*resp)(nil)
 )
 
-type ctxKey struct{}
-
-var kCtxKey = &ctxKey{} //nolint:gochecknoglobals
-
 // WithProps attaches shared props to the request context for later merging with response props.
 // Useful in middleware to provide global data (e.g., auth user, flash messages) to all pages.
 //
-// Response props take precedence over shared props when keys overlap.
+// It is equivalent to inertia.WithSharedProps, see it for the precedence rules.
 // Prefer setting props directly in responses when possible; use this for cross-cutting concerns.
 func WithProps(r *http.Request, props inertia.Proper) *http.Request {
-	return r.WithContext(context.WithValue(r.Context(), kCtxKey, props))
+	return inertia.WithSharedProps(r, props)
 }
 
+// DefaultRedirectBackTarget is the URL RedirectBack redirects to when
+// the previous page is unknown.
+//
+//nolint:gochecknoglobals
+var DefaultRedirectBackTarget = "/"
+
 // RedirectBack redirects to the previous page using the Referer header.
-// Falls back to the session-stored path if the header is missing, or "/" if no session.
+// Falls back to the last visited path (see PathCookieName) if the header is missing,
+// or DefaultRedirectBackTarget if neither is available.
 func RedirectBack(w http.ResponseWriter, r *http.Request) {
+	RedirectBackOr(w, r, DefaultRedirectBackTarget)
+}
+
+// RedirectBackOr is like RedirectBack, but redirects to fallback
+// if the previous page is unknown.
+func RedirectBackOr(w http.ResponseWriter, r *http.Request, fallback string) {
 	referer := r.Header.Get(inertiaheader.HeaderReferer)
 	if referer == "" {
-		sess, err := sessionFromRequest(r)
-		if err != nil {
-			d("failed to get session from request, using default '/'")
-
-			referer = "/"
-		} else {
-			referer = sess.Referer()
-		}
+		referer = pathFromRequest(r)
 	}
 
+	referer = cmp.Or(referer, fallback)
+
 	d("redirecting back to %s", referer)
 
 	inertiaredirect.Redirect(w, r, referer)
@@ -79,14 +125,50 @@ func RedirectBack(w http.ResponseWriter, r *http.Request) {
 
 // DefaultValidationErrorHandler handles validation errors by storing them in the session
 // and redirecting back to the previous page where they can be displayed.
+//
+// The errors are stored in the error bag requested by the client, unless
+// errorer implements inertia.ErrorBagger returning a non-empty error bag.
+// The request input is stored as well if SessionConfig.FlashInput is set.
+//
+// If the session exceeds the maximum cookie size, the input is dropped to
+// fit the errors. If the session still can't be saved, the error is handled
+// by httphandler.DefaultErrorHandler.
 func DefaultValidationErrorHandler(w http.ResponseWriter, r *http.Request, errorer inertia.ValidationErrorer) {
 	errorBag := inertia.ErrorBagFromRequest(r)
-	sess := must.Must(sessionFromRequest(r))
+	if bagger, ok := errorer.(inertia.ErrorBagger); ok && bagger.ErrorBag() != inertia.DefaultErrorBag {
+		errorBag = bagger.ErrorBag()
+	}
+
+	sess, err := sessionFromRequest(r)
+	if err != nil {
+		d("failed to get session from request, starting a new one: %v", err)
+
+		sess = &session{} //nolint:exhaustruct
+	}
 
 	sess.ErrorBag_ = errorBag
 	sess.ValidationErrors_ = errorer.ValidationErrors()
 
-	must.Must1(sess.Save(w))
+	if input := inputFromRequest(r); input != nil && sessionConfigFromRequest(r).FlashInput {
+		if b, err := json.Marshal(input); err != nil {
+			d("failed to encode input to flash: %v", err)
+		} else {
+			sess.OldInput_ = b
+		}
+	}
+
+	err = sess.Save(w, r)
+	if errors.Is(err, ErrSessionTooLarge) && sess.OldInput_ != nil {
+		d("session too large, dropping flashed input")
+
+		sess.OldInput_ = nil
+		err = sess.Save(w, r)
+	}
+
+	if err != nil {
+		httphandler.DefaultErrorHandler(w, r, fmt.Errorf("inertiaframe: failed to save validation errors: %w", err))
+		return
+	}
 
 	RedirectBack(w, r)
 }
@@ -100,6 +182,34 @@ var DefaultErrorHandler httphandler.ErrorHandler = httphandler.ErrorHandlerFunc(
 			return
 		}
 
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			if r.Header.Get(inertiaheader.HeaderXInertia) == "true" && r.Method == http.MethodGet {
+				inertia.Location(w, r, r.URL.RequestURI())
+				return
+			}
+
+			http.Error(w, httpErr.Error(), httpErr.Status)
+
+			return
+		}
+
+		if errors.Is(err, inertia.ErrUnknownPartialProp) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if errors.Is(err, ErrUnsupportedMediaType) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || errors.Is(err, ErrBodyTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
 		httphandler.DefaultErrorHandler(w, r, err)
 	},
 )
@@ -108,18 +218,41 @@ const (
 	mediaTypeJSON      = "application/json"
 	mediaTypeForm      = "application/x-www-form-urlencoded"
 	mediaTypeMultipart = "multipart/form-data"
+
+	mediaTypeOctetStream = "application/octet-stream"
 )
 
+// isJSONMediaType reports whether mediaType is JSON, including
+// structured syntax suffix types such as application/vnd.api+json.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == mediaTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
 // Request represents a parsed and validated client request.
 type Request[M any] struct {
 	// Message is the decoded request payload (from JSON or form data).
 	// If M implements RawRequestExtractor, custom extraction logic is used.
 	Message M
+
+	r *http.Request
 }
 
 // newRequest creates a new request.
-func newRequest[M any](m M) *Request[M] {
-	return &Request[M]{Message: m}
+func newRequest[M any](m M, r *http.Request) *Request[M] {
+	return &Request[M]{Message: m, r: r}
+}
+
+// PartialProps returns the prop keys requested by a partial reload of component,
+// see inertia.PartialProps.
+//
+// It allows responding with props on demand, which are resolved even if
+// they were not part of the initially rendered page.
+func (r *Request[M]) PartialProps(component string) []string {
+	if r.r == nil {
+		return nil
+	}
+
+	return inertia.PartialProps(r.r, component)
 }
 
 // ResponseOptions configures Inertia response behavior for a specific page.
@@ -132,15 +265,63 @@ type ResponseOptions struct {
 
 	// Concurrency sets the maximum concurrent lazy prop resolutions for this response.
 	Concurrency int
+
+	// PollInterval hints the client how long to wait before polling the page again,
+	// see inertia.WithPollInterval.
+	PollInterval time.Duration
+
+	// Status is the HTTP status code of the rendered page, e.g., 422 for a page
+	// displaying a soft failure or 201 after creating a resource. Defaults to 200 OK.
+	Status int
+
+	// Headers are set on the rendered response, e.g., Cache-Control: no-store
+	// for authenticated pages.
+	//
+	// Headers set by the renderer, such as Content-Type and X-Inertia, take precedence,
+	// and Vary values are added to the ones set by the Inertia middleware.
+	Headers http.Header
+
+	// CacheControl is the Cache-Control header of full page (HTML) responses.
+	// It takes precedence over the Cache-Control set in Headers.
+	CacheControl string
+
+	// InertiaCacheControl is the Cache-Control header of Inertia (JSON) responses.
+	// It takes precedence over the Cache-Control set in Headers.
+	InertiaCacheControl string
 }
 
 func (opt *ResponseOptions) defaults() {
-	opt.Concurrency = cmp.Or(opt.Concurrency, inertia.DefaultConcurrency)
+	opt.Concurrency = cmp.Or(opt.Concurrency, inertia.DefaultConcurrency())
 }
 
 // ResponseOption is used to configure inertia response.
 type ResponseOption func(*ResponseOptions)
 
+// NoStore instructs clients and proxies not to store the response,
+// both for full page loads and Inertia requests.
+func NoStore() ResponseOption {
+	return func(opts *ResponseOptions) {
+		opts.CacheControl = cacheControlNoStore
+		opts.InertiaCacheControl = cacheControlNoStore
+	}
+}
+
+// CacheFor allows clients and proxies to cache the HTML shell of a full page load for d.
+//
+// The last visited path used by RedirectBack (see PathCookieName) isn't recorded
+// for such pages, so that they don't set cookies.
+//
+// Inertia responses are dynamic and are not stored, so that the client never
+// shows stale page data.
+func CacheFor(d time.Duration) ResponseOption {
+	return func(opts *ResponseOptions) {
+		opts.CacheControl = "public, max-age=" + strconv.Itoa(int(d.Seconds()))
+		opts.InertiaCacheControl = cacheControlNoStore
+	}
+}
+
+const cacheControlNoStore = "no-store"
+
 // Response represents an endpoint's response, instructing the client to render a component or redirect.
 //
 // If a Response implements RawResponseWriter, it bypasses normal Inertia rendering
@@ -229,6 +410,89 @@ func (rr *rawResp) Write(w http.ResponseWriter, r *http.Request) error {
 	return rr.h.ServeHTTP(w, r)
 }
 
+type fileResp struct {
+	r           io.Reader
+	filename    string
+	contentType string
+}
+
+// NewFileResponse creates a Response that streams r to the client as a file attachment.
+// It bypasses Inertia rendering, similar to NewRawResponse.
+//
+// If contentType is empty, it is detected from the filename extension,
+// falling back to "application/octet-stream". If r implements io.Closer,
+// it is closed once the response is written.
+func NewFileResponse(filename string, contentType string, r io.Reader) Response {
+	return &fileResp{r, filename, contentType}
+}
+
+func (*fileResp) Component() string      { return "<file>" }
+func (*fileResp) Proper() inertia.Proper { return nil }
+
+func (fr *fileResp) Write(w http.ResponseWriter, _ *http.Request) error {
+	if c, ok := fr.r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	setFileHeaders(w, fr.filename, fr.contentType)
+
+	if _, err := io.Copy(w, fr.r); err != nil {
+		return fmt.Errorf("inertiaframe: failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+type fsFileResp struct {
+	fsys        fs.FS
+	name        string
+	contentType string
+}
+
+// NewFileResponseFromFS creates a Response that streams the named file from fsys
+// to the client as a file attachment. The file is opened when the response is written.
+//
+// See NewFileResponse for the content type detection rules.
+func NewFileResponseFromFS(fsys fs.FS, name string, contentType string) Response {
+	return &fsFileResp{fsys, name, contentType}
+}
+
+func (*fsFileResp) Component() string      { return "<file>" }
+func (*fsFileResp) Proper() inertia.Proper { return nil }
+
+func (fr *fsFileResp) Write(w http.ResponseWriter, _ *http.Request) error {
+	f, err := fr.fsys.Open(fr.name)
+	if err != nil {
+		return fmt.Errorf("inertiaframe: failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+		w.Header().Set(inertiaheader.HeaderContentLength, strconv.FormatInt(info.Size(), 10))
+	}
+
+	setFileHeaders(w, path.Base(fr.name), fr.contentType)
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("inertiaframe: failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// setFileHeaders sets the Content-Type and Content-Disposition headers
+// for a file attachment.
+func setFileHeaders(w http.ResponseWriter, filename string, contentType string) {
+	if contentType == "" {
+		contentType = cmp.Or(mime.TypeByExtension(path.Ext(filename)), mediaTypeOctetStream)
+	}
+
+	h := w.Header()
+	h.Set(inertiaheader.HeaderContentType, contentType)
+	h.Set(inertiaheader.HeaderContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
 type externalRedirectMessage struct{ url string }
 
 // NewExternalRedirectResponse creates a Response that redirects to an external URL
@@ -245,19 +509,41 @@ func (m *externalRedirectMessage) Write(w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
-type redirectBackMessage struct{}
+type downloadMessage struct{ url string }
+
+// NewDownloadResponse creates a Response that makes the client navigate to
+// a downloadable resource. See inertia.Download for details.
+func NewDownloadResponse(url string) Response {
+	return &downloadMessage{url: url}
+}
+
+func (m *downloadMessage) Proper() inertia.Proper { return nil }
+func (m *downloadMessage) Component() string      { return "" }
+
+func (m *downloadMessage) Write(w http.ResponseWriter, r *http.Request) error {
+	inertia.Download(w, r, m.url)
+	return nil
+}
+
+type redirectBackMessage struct{ fallback string }
 
 // NewRedirectBackResponse creates a Response that redirects to the previous page.
-// Uses the Referer header or session-stored path.
+// Uses the Referer header or the last visited path, see RedirectBack.
 func NewRedirectBackResponse() Response {
-	return &redirectBackMessage{}
+	return &redirectBackMessage{fallback: ""}
+}
+
+// NewRedirectBackOrResponse is like NewRedirectBackResponse, but redirects
+// to fallback if the previous page is unknown, see RedirectBackOr.
+func NewRedirectBackOrResponse(fallback string) Response {
+	return &redirectBackMessage{fallback: fallback}
 }
 
 func (m *redirectBackMessage) Proper() inertia.Proper { return nil }
 func (m *redirectBackMessage) Component() string      { return "" }
 
 func (m *redirectBackMessage) Write(w http.ResponseWriter, r *http.Request) error {
-	RedirectBack(w, r)
+	RedirectBackOr(w, r, cmp.Or(m.fallback, DefaultRedirectBackTarget))
 	return nil
 }
 
@@ -276,6 +562,28 @@ func (m *redirectMessage) Write(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+type noContentMessage struct{}
+
+// NewNoContentResponse creates a Response writing an empty 204 No Content response,
+// for endpoints intentionally leaving the client on the current page.
+func NewNoContentResponse() Response {
+	return &noContentMessage{}
+}
+
+func (m *noContentMessage) Proper() inertia.Proper { return nil }
+func (m *noContentMessage) Component() string      { return "" }
+
+func (m *noContentMessage) Write(w http.ResponseWriter, _ *http.Request) error {
+	// The client would expect a page along with these headers.
+	h := w.Header()
+	h.Del(inertiaheader.HeaderXInertia)
+	h.Del(inertiaheader.HeaderContentType)
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
 // RawRequestExtractor allows custom request parsing logic.
 // When a request message implements this interface, it bypasses the default
 // JSON/form decoder and calls Extract instead.
@@ -291,6 +599,15 @@ type RawResponseWriter interface {
 	Write(http.ResponseWriter, *http.Request) error
 }
 
+// RawPropsWriter is a RawResponseWriter alternative receiving the resolved props
+// of the Response. Useful for writing the same props as a page or, e.g., as a file download.
+//
+// Only the props returned by Response.Proper are resolved, including lazy ones;
+// shared props are not included.
+type RawPropsWriter interface {
+	WriteProps(w http.ResponseWriter, r *http.Request, props map[string]any) error
+}
+
 // ResponseOptioner is an optional interface for Responses that need custom options
 // (history management, concurrency). If implemented, Options() is called to configure the response.
 type ResponseOptioner interface {
@@ -329,6 +646,15 @@ type Endpoint[M any] interface {
 	Meta() Meta
 }
 
+// PreExecutor is an optional interface for endpoints that need to inspect the request
+// before the request body is decoded, e.g., to reject requests when a feature is disabled.
+type PreExecutor interface {
+	// PreExecute is called before the request is decoded. Returning a non-nil Response
+	// short-circuits the request: the Response is written and Execute is not called.
+	// Returning nil continues processing the request.
+	PreExecute(context.Context, *http.Request) (Response, error)
+}
+
 // Mux represents an HTTP router compatible with http.ServeMux.
 type Mux interface {
 	// Handle registers a handler for a pattern (e.g., "POST /users/{id}").
@@ -340,7 +666,8 @@ type MountOpts[M any] struct {
 	// Validator validates requests before execution. If nil, no validation is performed.
 	Validator Validator[M]
 
-	// FormDecoder parses form-urlencoded and multipart requests.
+	// FormDecoder parses form-urlencoded and multipart requests,
+	// and query parameters of GET requests.
 	// Defaults to DefaultFormDecoder if nil.
 	FormDecoder *form.Decoder
 
@@ -349,25 +676,44 @@ type MountOpts[M any] struct {
 
 	// JSONUnmarshalOptions customizes JSON parsing (e.g., for protobuf).
 	JSONUnmarshalOptions []json.Options
+
+	// SessionConfig configures the session cookie used to flash validation errors.
+	// If nil, default cookie attributes are used.
+	SessionConfig *SessionConfig
+
+	// MaxBodyBytes limits the size in bytes of request bodies, requests exceeding it
+	// are rejected with 413 Request Entity Too Large by DefaultErrorHandler.
+	// Defaults to DefaultMaxBodyBytes if zero. Negative values disable the limit.
+	MaxBodyBytes int64
+
+	// MaxDecompressedBodySize limits the size in bytes of gzip-encoded request bodies
+	// after decompression, guarding against decompression bombs.
+	// Defaults to DefaultMaxDecompressedBodySize if zero. Negative values disable the limit.
+	MaxDecompressedBodySize int64
+
+	// DisableHead disables registering a HEAD handler for GET endpoints.
+	//
+	// By default, Mount registers the endpoint for HEAD requests as well, responding
+	// with the headers of the GET response without the body. Disable it to mount
+	// a dedicated HEAD endpoint on the same path.
+	DisableHead bool
+
+	// CORS enables cross-origin requests to the endpoint. When set, CORS headers
+	// are added to the endpoint's responses. Preflight OPTIONS requests to the
+	// endpoint's path are served by the handler registered with MountCORS.
+	// If nil, no CORS headers are sent.
+	CORS *CORSConfig
 }
 
 // Mount registers an Endpoint on a Mux, creating an HTTP handler that:
-//   - Automatically parses JSON and form data into the message type M
+//   - Automatically parses JSON and form data, or query parameters of GET requests,
+//     into the message type M, and path values into fields tagged with TagPath
 //   - Validates requests using the configured Validator
 //   - Executes the endpoint and renders the Response
 //
 // The endpoint's Meta() defines the HTTP method and path pattern.
 func Mount[M any](mux Mux, endpoint Endpoint[M], opts *MountOpts[M]) {
-	if opts == nil {
-		//nolint:exhaustruct
-		opts = &MountOpts[M]{}
-	}
-
-	opts.ErrorHandler = cmp.Or(opts.ErrorHandler, DefaultErrorHandler)
-	opts.FormDecoder = cmp.Or(opts.FormDecoder, DefaultFormDecoder)
-
 	debug.Assert(endpoint != nil, "Executor must not be nil")
-	debug.Assert(opts.ErrorHandler != nil, "Executor must specify the error handler")
 
 	m := endpoint.Meta()
 
@@ -378,146 +724,322 @@ func Mount[M any](mux Mux, endpoint Endpoint[M], opts *MountOpts[M]) {
 
 	d("Mounting executor on pattern: %s", pattern)
 
-	mux.Handle(
-		pattern,
-		newHandler(
-			endpoint,
-			opts.ErrorHandler,
-			opts.Validator,
-			opts.FormDecoder,
-			opts.JSONUnmarshalOptions,
-		),
-	)
+	h := NewEndpointHandler(endpoint, opts)
+
+	mux.Handle(pattern, h)
+
+	if m.Method == http.MethodGet && (opts == nil || !opts.DisableHead) {
+		mux.Handle(fmt.Sprintf("%s %s", http.MethodHead, m.Path), h)
+	}
 }
 
-// newHandler creates a new http.Handler for the given endpoint.
-func newHandler[M any](
-	endpoint Endpoint[M],
-	errorHandler httphandler.ErrorHandler,
-	validator Validator[M],
-	formDecoder *form.Decoder,
-	jsonUnmarshalOptions []json.Options,
-) http.Handler {
-	handleError := httphandler.WithErrorHandler(errorHandler)
+// NewEndpointHandler returns the http.Handler Mount registers for the endpoint,
+// e.g., to test an endpoint with httptest without a Mux.
+//
+// The handler doesn't check the request method and path against the endpoint's Meta,
+// nor does it respond to CORS preflight requests, see MountCORS.
+func NewEndpointHandler[M any](endpoint Endpoint[M], opts *MountOpts[M]) http.Handler {
+	debug.Assert(endpoint != nil, "Executor must not be nil")
 
-	return handleError(httphandler.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
-		var (
-			msg       M
-			renderCtx inertia.RenderContext
-		)
+	var c *cors
+	if opts != nil && opts.CORS != nil {
+		c = newCORS(opts.CORS)
+	}
 
-		ctx := r.Context()
+	return newEndpointHandler(endpoint, opts, c)
+}
 
-		if extract, ok := any(msg).(RawRequestExtractor); ok {
-			if err := extract.Extract(r); err != nil {
-				return fmt.Errorf("inertiaframe: failed to extract request data: %w", err)
-			}
-		} else if r.Method != http.MethodGet {
-			mediaType, _, err := mime.ParseMediaType(
-				r.Header.Get(inertiaheader.HeaderContentType))
-			if err != nil {
-				return fmt.Errorf("inertiaframe: failed to parse Content-Type header: %w", err)
-			}
+// newEndpointHandler creates the handler of the endpoint, adding the CORS headers of c if non-nil.
+func newEndpointHandler[M any](endpoint Endpoint[M], opts *MountOpts[M], c *cors) http.Handler {
+	if opts == nil {
+		//nolint:exhaustruct
+		opts = &MountOpts[M]{}
+	}
 
-			// Inertia accepts only JSON or multipart/form-data.
-			switch mediaType {
-			case mediaTypeJSON:
-				{
-					d("received JSON request")
-
-					if err := json.UnmarshalRead(
-						r.Body,
-						&msg,
-						jsonUnmarshalOptions...); err != nil {
-						return fmt.Errorf("inertiaframe: failed to decode request: %w", err)
-					}
-				}
-			case mediaTypeForm, mediaTypeMultipart:
-				{
-					d("received form request")
+	opts.ErrorHandler = cmp.Or(opts.ErrorHandler, DefaultErrorHandler)
+	opts.FormDecoder = cmp.Or(opts.FormDecoder, DefaultFormDecoder)
+	opts.MaxBodyBytes = cmp.Or(opts.MaxBodyBytes, DefaultMaxBodyBytes)
+	opts.MaxDecompressedBodySize = cmp.Or(opts.MaxDecompressedBodySize, DefaultMaxDecompressedBodySize)
 
-					if err := r.ParseForm(); err != nil {
-						return fmt.Errorf("inertiaframe: failed to parse form data: %w", err)
-					}
+	//nolint:exhaustruct
+	opts.SessionConfig = cmp.Or(opts.SessionConfig, &SessionConfig{})
+	opts.SessionConfig.defaults()
 
-					if err := formDecoder.Decode(&msg, r.Form); err != nil {
-						return fmt.Errorf("inertiaframe: failed to decode form data: %w", err)
-					}
-				}
+	debug.Assert(opts.ErrorHandler != nil, "Executor must specify the error handler")
+
+	h := newHandler(
+		endpoint,
+		opts.ErrorHandler,
+		opts.Validator,
+		opts.FormDecoder,
+		opts.JSONUnmarshalOptions,
+		opts.SessionConfig,
+		opts.MaxBodyBytes,
+		opts.MaxDecompressedBodySize,
+	)
+
+	if c != nil {
+		h = c.handler(h)
+	}
+
+	return h
+}
+
+// setHeaders sets the headers h on the response, adding Vary values
+// to the existing ones instead of replacing them.
+func setHeaders(w http.ResponseWriter, h http.Header) {
+	dst := w.Header()
+
+	for key, values := range h {
+		key = http.CanonicalHeaderKey(key)
+		if key == inertiaheader.HeaderVary {
+			for _, v := range values {
+				dst.Add(key, v)
 			}
+
+			continue
 		}
 
-		if validator != nil {
-			if err := validator.Validate(msg); err != nil {
-				d("failed to validate request")
+		dst[key] = slices.Clone(values)
+	}
+}
 
-				return fmt.Errorf("inertiaframe: failed to validate request: %w", err)
-			}
+// setCacheControl sets the Cache-Control header for the kind of the response,
+// see ResponseOptions.CacheControl and ResponseOptions.InertiaCacheControl.
+func setCacheControl(w http.ResponseWriter, r *http.Request, opts ResponseOptions) {
+	cacheControl := opts.CacheControl
+	if r.Header.Get(inertiaheader.HeaderXInertia) == "true" {
+		cacheControl = opts.InertiaCacheControl
+	}
+
+	if cacheControl == "" {
+		return
+	}
+
+	h := w.Header()
+	h.Set(inertiaheader.HeaderCacheControl, cacheControl)
+
+	// Responses differ by the X-Inertia header, caches must key on it.
+	if !slices.Contains(h.Values(inertiaheader.HeaderVary), inertiaheader.HeaderXInertia) {
+		h.Add(inertiaheader.HeaderVary, inertiaheader.HeaderXInertia)
+	}
+}
+
+// isPublicCacheControl reports whether cacheControl allows shared caches
+// to store the response.
+func isPublicCacheControl(cacheControl string) bool {
+	for directive := range strings.SplitSeq(cacheControl, ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "public") {
+			return true
 		}
+	}
+
+	return false
+}
+
+// writeResponse writes resp to w, rendering the response component
+// unless resp writes the response itself.
+func writeResponse(w http.ResponseWriter, r *http.Request, resp Response) error {
+	if resp == nil {
+		d("received empty response")
+
+		return ErrEmptyResponse
+	}
 
-		resp, err := endpoint.Execute(ctx, newRequest(msg))
+	if writer, ok := resp.(RawPropsWriter); ok {
+		props, err := inertia.ResolveProps(r.Context(), resp.Proper())
 		if err != nil {
-			return fmt.Errorf("inertiaframe: failed to execute: %w", err)
+			return fmt.Errorf("inertiaframe: failed to resolve props: %w", err)
 		}
 
-		if resp == nil {
-			d("received empty response")
-
-			return ErrEmptyResponse
+		if err := writer.WriteProps(w, r, props); err != nil {
+			return fmt.Errorf("inertiaframe: failed to write response: %w", err)
 		}
 
-		if writer, ok := resp.(RawResponseWriter); ok {
-			if err := writer.Write(w, r); err != nil {
-				return fmt.Errorf("inertiaframe: failed to write response: %w", err)
-			}
+		return nil
+	}
 
-			return nil
+	if writer, ok := resp.(RawResponseWriter); ok {
+		if err := writer.Write(w, r); err != nil {
+			return fmt.Errorf("inertiaframe: failed to write response: %w", err)
 		}
 
-		if optioner, ok := resp.(ResponseOptioner); ok {
-			opts := optioner.Options()
+		return nil
+	}
+
+	var (
+		renderCtx   inertia.RenderContext
+		publicCache bool
+	)
+
+	if optioner, ok := resp.(ResponseOptioner); ok {
+		opts := optioner.Options()
+		publicCache = isPublicCacheControl(opts.CacheControl)
+
+		renderCtx.ClearHistory = opts.ClearHistory
+		renderCtx.EncryptHistory = opts.EncryptHistory
+		renderCtx.Concurrency = opts.Concurrency
+		renderCtx.StatusCode = opts.Status
+		renderCtx.PollInterval = opts.PollInterval
+
+		setHeaders(w, opts.Headers)
+		setCacheControl(w, r, opts)
+	}
+
+	// Props are passed as is, the renderer merges them with shared props
+	// and reports duplicate keys, see inertia.Config.StrictProps.
+	if proper := resp.Proper(); proper != nil {
+		renderCtx.Props = proper.Props()
+	}
+
+	sess, err := sessionFromRequest(r)
+	if err != nil {
+		return fmt.Errorf("inertiaframe: failed to get session: %w", err)
+	}
+
+	// Render the flashed errors under the bag they were stored with,
+	// the X-Inertia-Error-Bag header of this request may differ.
+	errorBag := sess.ErrorBag()
+	errors := sess.ValidationErrors()
+	oldInput := sess.OldInput()
 
-			renderCtx.ClearHistory = opts.ClearHistory
-			renderCtx.EncryptHistory = opts.EncryptHistory
-			renderCtx.Concurrency = opts.Concurrency
+	if errors != nil {
+		renderCtx.ErrorBag = errorBag
+		renderCtx.AddValidationErrorer(inertia.ValidationErrors(errors))
+	}
+
+	if oldInput != nil {
+		var input map[string]any
+		if err := json.Unmarshal(oldInput, &input); err != nil {
+			return fmt.Errorf("inertiaframe: failed to decode old input: %w", err)
 		}
 
-		var props []inertia.Prop
+		// Page props with the same key take precedence.
+		r = inertia.WithSharedProps(r, inertia.NewProp(OldInputPropKey, input, nil))
+	}
+
+	// Remember the page loaded in full, so that RedirectBack can fall back to it
+	// when the Referer header is missing. Inertia visits are made from a loaded
+	// page, and publicly cached responses must not set cookies.
+	if path := r.URL.RequestURI(); r.Method == http.MethodGet &&
+		r.Header.Get(inertiaheader.HeaderXInertia) != "true" &&
+		!publicCache &&
+		pathFromRequest(r) != path {
+		savePath(w, r, path)
+	}
 
-		if proper, ok := r.Context().Value(kCtxKey).(inertia.Proper); ok {
-			d("has shared props")
+	// The flashed data is consumed, clear the session so that
+	// a subsequent refresh doesn't show it again.
+	if errors != nil || oldInput != nil || sess.stale {
+		sess.Clear(w, r)
+	}
+
+	component := resp.Component()
+	debug.Assert(component != "", "component must not be empty, when using non RawResponseWriter")
+
+	if err := inertia.Render(w, r, component, renderCtx); err != nil {
+		return fmt.Errorf("inertiaframe: failed to render: %w", err)
+	}
 
-			props = proper.Props()
+	return nil
+}
+
+// newHandler creates a new http.Handler for the given endpoint.
+func newHandler[M any](
+	endpoint Endpoint[M],
+	errorHandler httphandler.ErrorHandler,
+	validator Validator[M],
+	formDecoder *form.Decoder,
+	jsonUnmarshalOptions []json.Options,
+	sessionConfig *SessionConfig,
+	maxBodyBytes int64,
+	maxDecompressedBodySize int64,
+) http.Handler {
+	// serve returns the request derived while handling, so that the error
+	// handler can access the attached request data, e.g., the flashed input.
+	serve := func(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+		var msg M
+
+		if maxBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
 		}
 
-		proper := resp.Proper()
-		if proper.Len() > 0 {
-			d("response has props")
+		if pre, ok := endpoint.(PreExecutor); ok {
+			resp, err := pre.PreExecute(r.Context(), r)
+			if err != nil {
+				return r, fmt.Errorf("inertiaframe: failed to pre-execute: %w", err)
+			}
+
+			if resp != nil {
+				d("pre-execute short-circuited the request")
 
-			props = append(props, proper.Props()...)
+				return r, writeResponse(w, r, resp)
+			}
 		}
 
-		renderCtx.Props = props
+		if err := decompressBody(r, maxDecompressedBodySize); err != nil {
+			return r, err
+		}
 
-		sess, err := sessionFromRequest(r)
-		if err != nil {
-			return fmt.Errorf("inertiaframe: failed to get session: %w", err)
+		if extract, ok := any(msg).(RawRequestExtractor); ok {
+			if err := extract.Extract(r); err != nil {
+				return r, fmt.Errorf("inertiaframe: failed to extract request data: %w", err)
+			}
+		} else {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				if err := formDecoder.Decode(&msg, r.URL.Query()); err != nil {
+					return r, fmt.Errorf("inertiaframe: failed to decode query parameters: %w", err)
+				}
+			} else {
+				var body *bytes.Buffer
+				if sessionConfig.FlashInput && r.Body != nil {
+					body = captureBody(r)
+				}
+
+				if err := decodeBody(r, &msg, formDecoder, jsonUnmarshalOptions); err != nil {
+					return r, err
+				}
+
+				if body != nil {
+					r = withInput(r, body.Bytes(), sessionConfig.FlashInputExclude)
+				}
+			}
+
+			// Path values take precedence over the query and body.
+			if err := decodePathValues(r, &msg); err != nil {
+				return r, err
+			}
 		}
 
-		errors := sess.ValidationErrors()
+		if validator != nil {
+			if err := validator.Validate(msg); err != nil {
+				d("failed to validate request")
 
-		if errors != nil {
-			renderCtx.ErrorBag = sess.ErrorBag()
-			renderCtx.AddValidationErrorer(inertia.ValidationErrors(errors))
+				return r, fmt.Errorf("inertiaframe: failed to validate request: %w", err)
+			}
 		}
 
-		component := resp.Component()
-		debug.Assert(component != "", "component must not be empty, when using non RawResponseWriter")
+		// Load the session into the request context, so that the endpoint can read
+		// the flashed validation errors, see ValidationErrorsFromContext.
+		// Decoding failures are reported when the response is rendered.
+		if _, err := sessionFromRequest(r); err != nil {
+			d("failed to load session: %v", err)
+		}
 
-		if err := inertia.Render(w, r, component, renderCtx); err != nil {
-			return fmt.Errorf("inertiaframe: failed to render: %w", err)
+		resp, err := endpoint.Execute(r.Context(), newRequest(msg, r))
+		if err != nil {
+			return r, fmt.Errorf("inertiaframe: failed to execute: %w", err)
 		}
 
-		return nil
-	}))
+		return r, writeResponse(w, r, resp)
+	}
+
+	// Attach the session config before the error handler runs, so that
+	// the validation error handler saves the session with the same attributes.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, err := serve(w, withSessionConfig(r, sessionConfig))
+		if err != nil {
+			errorHandler.ServeHTTP(w, r, err)
+		}
+	})
 }
@@ -0,0 +1,2120 @@
+package inertiaframe
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.inout.gg/foundations/http/httphandler"
+
+	"go.segfaultmedaddy.com/inertia"
+	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
+	"go.segfaultmedaddy.com/inertia/internal/inertiatest"
+)
+
+type testEndpoint[M any] struct {
+	execute func(context.Context, *Request[M]) (Response, error)
+	meta    Meta
+}
+
+func (e *testEndpoint[M]) Meta() Meta { return e.meta }
+
+func (e *testEndpoint[M]) Execute(ctx context.Context, r *Request[M]) (Response, error) {
+	return e.execute(ctx, r)
+}
+
+// newTestMux mounts a GET endpoint returning resp on a mux without the inertia
+// middleware, so any attempt to render an Inertia page fails.
+func newTestMux(resp Response) *http.ServeMux {
+	mux := http.NewServeMux()
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/test"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return resp, nil
+		},
+	}, nil)
+
+	return mux
+}
+
+func TestFileResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewFileResponse streams the reader as an attachment", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(NewFileResponse("report.csv", "text/csv", strings.NewReader("a,b\n1,2\n")))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename=report.csv`, w.Header().Get(inertiaheader.HeaderContentDisposition))
+		assert.Equal(t, "text/csv", w.Header().Get(inertiaheader.HeaderContentType))
+		assert.Empty(t, w.Header().Get(inertiaheader.HeaderXInertia))
+		assert.Equal(t, "a,b\n1,2\n", w.Body.String())
+	})
+
+	t.Run("NewFileResponse detects content type from the filename", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(NewFileResponse("data.json", "", strings.NewReader("{}")))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get(inertiaheader.HeaderContentType))
+	})
+
+	t.Run("NewFileResponseFromFS streams the file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		fsys := fstest.MapFS{"files/hello.txt": &fstest.MapFile{Data: []byte("hello")}}
+		mux := newTestMux(NewFileResponseFromFS(fsys, "files/hello.txt", "text/plain"))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename=hello.txt`, w.Header().Get(inertiaheader.HeaderContentDisposition))
+		assert.Equal(t, "5", w.Header().Get(inertiaheader.HeaderContentLength))
+		assert.Equal(t, "hello", w.Body.String())
+	})
+
+	t.Run("NewFileResponseFromFS fails on missing file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(NewFileResponseFromFS(fstest.MapFS{}, "missing.txt", ""))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.NotEqual(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(inertiaheader.HeaderContentDisposition))
+	})
+}
+
+func TestDownloadResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inertia request uses location conflict", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(NewDownloadResponse("/files/report.pdf"))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "/files/report.pdf", w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+	})
+
+	t.Run("non-inertia request redirects", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(NewDownloadResponse("/files/report.pdf"))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/files/report.pdf", w.Header().Get("Location"))
+	})
+}
+
+// csvResponse writes the resolved props of Response as a CSV file.
+type csvResponse struct{ Response }
+
+func (csvResponse) WriteProps(w http.ResponseWriter, _ *http.Request, props map[string]any) error {
+	keys := slices.Sorted(maps.Keys(props))
+
+	w.Header().Set(inertiaheader.HeaderContentType, "text/csv")
+
+	cw := csv.NewWriter(w)
+	for _, k := range keys {
+		if err := cw.Write([]string{k, fmt.Sprint(props[k])}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func TestRawPropsWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes resolved props", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(csvResponse{NewResponse("Report", inertia.Props{
+			inertia.NewProp("total", 42, nil),
+			inertia.NewDeferred("rows", inertia.LazyFunc(func(context.Context) (any, error) {
+				return 3, nil
+			}), nil),
+			inertia.PropIf(false, inertia.NewProp("hidden", true, nil)),
+		})})
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get(inertiaheader.HeaderContentType))
+		assert.Equal(t, "rows,3\ntotal,42\n", w.Body.String())
+	})
+
+	t.Run("fails when a prop fails to resolve", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := newTestMux(csvResponse{NewResponse("Report", inertia.Props{
+			inertia.NewOptional("rows", inertia.LazyFunc(func(context.Context) (any, error) {
+				return nil, errors.New("boom")
+			})),
+		})})
+		r, w := inertiatest.NewRequest(http.MethodGet, "/test", nil)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.NotEqual(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, "text/csv", w.Header().Get(inertiaheader.HeaderContentType))
+	})
+}
+
+type testMessage struct {
+	Name string `json:"name"`
+}
+
+func TestSessionCookie(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	mux := http.NewServeMux()
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/test"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewRedirectBackResponse(), nil
+		},
+	}, &MountOpts[testMessage]{
+		Validator: ValidatorFunc[testMessage](func(testMessage) error {
+			return inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+		}),
+		SessionConfig: &SessionConfig{
+			Domain:   "example.com",
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		},
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":""}`))
+	r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+	r.Header.Set(inertiaheader.HeaderReferer, "/form")
+
+	w := httptest.NewRecorder()
+
+	// act
+	mux.ServeHTTP(w, r)
+
+	// assert
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/form", w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	cookie := cookies[0]
+	assert.Equal(t, SessionCookieName, cookie.Name)
+	assert.Equal(t, "example.com", cookie.Domain)
+	assert.Equal(t, SessionPath, cookie.Path)
+	assert.Equal(t, DefaultSessionMaxAge, cookie.MaxAge)
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+
+	// The session cookie is sent back with the next request.
+	next := httptest.NewRequest(http.MethodGet, "/form", nil)
+	next.AddCookie(cookie)
+
+	sess, err := sessionFromRequest(next)
+	require.NoError(t, err)
+
+	errs := sess.ValidationErrors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "name", errs[0].Field())
+	assert.Equal(t, "Name is required", errs[0].Error())
+}
+
+// newFlashTestHandler creates an inertia handler serving a form page on GET /form
+// and a form submission failing validation on POST /form.
+func newFlashTestHandler() http.Handler {
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodGet, Path: "/form"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewResponse("Form", inertia.Props{}), nil
+		},
+	}, nil)
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/form"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewRedirectBackResponse(), nil
+		},
+	}, &MountOpts[testMessage]{
+		Validator: ValidatorFunc[testMessage](func(testMessage) error {
+			return inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+		}),
+	})
+
+	return handler
+}
+
+// newInvalidFormRequest creates an Inertia form submission failing validation.
+func newInvalidFormRequest() (*http.Request, *httptest.ResponseRecorder) {
+	r, w := inertiatest.NewRequest(http.MethodPost, "/form", &inertiatest.RequestConfig{Inertia: true})
+	r.Body = io.NopCloser(strings.NewReader(`{"name":""}`))
+	r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+	r.Header.Set(inertiaheader.HeaderReferer, "/form")
+
+	return r, w
+}
+
+// pageErrors extracts the errors prop from an Inertia JSON response.
+func pageErrors(t *testing.T, body []byte) map[string]string {
+	t.Helper()
+
+	var page struct {
+		Props struct {
+			Errors map[string]string `json:"errors"`
+		} `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(body, &page))
+
+	return page.Props.Errors
+}
+
+func TestValidationErrorsFlash(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+
+	// act: submit an invalid form
+	r, w := newInvalidFormRequest()
+	handler.ServeHTTP(w, r)
+
+	// assert: the client is redirected back with the session cookie
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	require.Equal(t, "/form", w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Positive(t, cookies[0].MaxAge)
+	assert.True(t, cookies[0].Expires.After(time.Now()))
+
+	// act: follow the redirect
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	r.AddCookie(cookies[0])
+
+	handler.ServeHTTP(w, r)
+
+	// assert: the flashed errors are rendered
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]string{"name": "Name is required"}, pageErrors(t, w.Body.Bytes()))
+}
+
+func TestValidationErrorsFlashClearedAfterRender(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+	u := &url.URL{Scheme: "http", Host: "example.com", Path: "/"}
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	visit := func(r *http.Request, w *httptest.ResponseRecorder) {
+		for _, c := range jar.Cookies(u) {
+			r.AddCookie(c)
+		}
+
+		handler.ServeHTTP(w, r)
+		jar.SetCookies(u, w.Result().Cookies())
+	}
+
+	// act: submit an invalid form and follow the redirect
+	r, w := newInvalidFormRequest()
+	visit(r, w)
+	require.Equal(t, http.StatusSeeOther, w.Code)
+
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	visit(r, w)
+
+	// assert: the errors are shown once
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]string{"name": "Name is required"}, pageErrors(t, w.Body.Bytes()))
+
+	// act: refresh the page
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	visit(r, w)
+
+	// assert: the errors are gone
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, pageErrors(t, w.Body.Bytes()))
+}
+
+type testSessionStore struct {
+	m  map[string][]byte
+	mu sync.Mutex
+}
+
+func (s *testSessionStore) Save(_ context.Context, id string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.m[id] = data
+
+	return nil
+}
+
+func (s *testSessionStore) Load(_ context.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.m[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return data, nil
+}
+
+func (s *testSessionStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.m, id)
+
+	return nil
+}
+
+func TestDefaultValidationErrorHandler_SessionTooLarge(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(flashInput bool, input map[string]any) (*http.Request, *httptest.ResponseRecorder) {
+		//nolint:exhaustruct
+		config := &SessionConfig{FlashInput: flashInput}
+		config.defaults()
+
+		r := withSessionConfig(httptest.NewRequest(http.MethodPost, "/form", nil), config)
+		r.Header.Set(inertiaheader.HeaderReferer, "/form")
+
+		if input != nil {
+			r = r.WithContext(context.WithValue(r.Context(), kInputCtx, input))
+		}
+
+		return r, httptest.NewRecorder()
+	}
+
+	t.Run("oversized errors are handled without panicking", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errs := make(inertia.ValidationErrors, 0, 500)
+		for i := range 500 {
+			errs = append(errs, inertia.NewValidationError(
+				fmt.Sprintf("rows.%d.email", i), "The email field must be a valid email address."))
+		}
+
+		r, w := newRequest(false, nil)
+
+		// act
+		assert.NotPanics(t, func() { DefaultValidationErrorHandler(w, r, errs) })
+
+		// assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("oversized input is dropped", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errs := inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+		r, w := newRequest(true, map[string]any{"bio": strings.Repeat("a", 8000)})
+
+		// act
+		DefaultValidationErrorHandler(w, r, errs)
+
+		// assert
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/form", w.Header().Get("Location"))
+
+		cookies := w.Result().Cookies()
+		require.NotEmpty(t, cookies)
+
+		next := httptest.NewRequest(http.MethodGet, "/form", nil)
+		next.AddCookie(cookies[len(cookies)-1])
+
+		sess, err := sessionFromRequest(next)
+		require.NoError(t, err)
+		assert.Len(t, sess.ValidationErrors_, 1)
+		assert.Nil(t, sess.OldInput_)
+	})
+}
+
+func TestSessionOverflowCookieWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: overflowPrefix + "unknown"}) //nolint:exhaustruct
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var sessCookie *http.Cookie
+
+	for _, c := range w.Result().Cookies() {
+		if c.Name == SessionCookieName {
+			sessCookie = c
+		}
+	}
+
+	require.NotNil(t, sessCookie, "stale overflow cookie must be cleared")
+	assert.Negative(t, sessCookie.MaxAge)
+}
+
+func TestSessionSizeGuard(t *testing.T) {
+	t.Parallel()
+
+	largeSession := func() *session {
+		errs := make([]inertia.ValidationError, 0, 500)
+		for i := range 500 {
+			errs = append(errs, inertia.NewValidationError(
+				fmt.Sprintf("rows.%d.email", i), "The email field must be a valid email address."))
+		}
+
+		//nolint:exhaustruct
+		return &session{ValidationErrors_: errs}
+	}
+
+	t.Run("returns an error without a store", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		//nolint:exhaustruct
+		config := &SessionConfig{}
+		config.defaults()
+
+		r := withSessionConfig(httptest.NewRequest(http.MethodPost, "/", nil), config)
+		w := httptest.NewRecorder()
+
+		// act
+		err := largeSession().Save(w, r)
+
+		// assert
+		require.ErrorIs(t, err, ErrSessionTooLarge)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("overflows to the store", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		store := &testSessionStore{m: make(map[string][]byte)}
+
+		//nolint:exhaustruct
+		config := &SessionConfig{Store: store}
+		config.defaults()
+
+		r := withSessionConfig(httptest.NewRequest(http.MethodPost, "/", nil), config)
+		w := httptest.NewRecorder()
+
+		// act
+		err := largeSession().Save(w, r)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, store.m, 1)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.LessOrEqual(t, len(cookies[0].Value), 64)
+
+		next := withSessionConfig(httptest.NewRequest(http.MethodGet, "/", nil), config)
+		next.AddCookie(cookies[0])
+
+		sess, err := sessionFromRequest(next)
+		require.NoError(t, err)
+		assert.Len(t, sess.ValidationErrors(), 500)
+
+		sess.Clear(httptest.NewRecorder(), next)
+		assert.Empty(t, store.m)
+	})
+}
+
+func TestValidationErrorsFlashToErrorBag(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodGet, Path: "/form"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewResponse("Form", inertia.Props{}), nil
+		},
+	}, nil)
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/form"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewRedirectBackResponse(), nil
+		},
+	}, &MountOpts[testMessage]{
+		Validator: ValidatorFunc[testMessage](func(testMessage) error {
+			return inertia.InErrorBag(inertia.ValidationErrors{
+				inertia.NewValidationError("name", "Name is required"),
+			}, "login")
+		}),
+	})
+
+	// act: submit an invalid form and follow the redirect
+	r, w := newInvalidFormRequest()
+	handler.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusSeeOther, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	r.AddCookie(cookies[0])
+	handler.ServeHTTP(w, r)
+
+	// assert: the errors are scoped to the error bag
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Props map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.NotContains(t, page.Props, "errors")
+	assert.Equal(t, map[string]any{"errors": map[string]any{"name": "Name is required"}}, page.Props["login"])
+}
+
+func TestValidationErrorsFlashErrorBagRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+
+	newHandler := func() http.Handler {
+		mux := http.NewServeMux()
+
+		Mount(mux, &testEndpoint[testMessage]{
+			meta: Meta{Method: http.MethodGet, Path: "/form"},
+			execute: func(context.Context, *Request[testMessage]) (Response, error) {
+				return NewResponse("Form", inertia.Props{}), nil
+			},
+		}, nil)
+		Mount(mux, &testEndpoint[testMessage]{
+			meta: Meta{Method: http.MethodPost, Path: "/form"},
+			execute: func(context.Context, *Request[testMessage]) (Response, error) {
+				return NewRedirectBackResponse(), nil
+			},
+		}, &MountOpts[testMessage]{
+			Validator: ValidatorFunc[testMessage](func(testMessage) error {
+				return inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+			}),
+		})
+
+		return inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+	}
+
+	tests := []struct {
+		name      string
+		postBag   string
+		getBag    string
+		expectKey string
+	}{
+		{"custom bag", "login", "login", "login"},
+		{"custom bag without header on follow-up", "login", "", "login"},
+		{"custom bag with another header on follow-up", "login", "signup", "login"},
+		{"default bag with custom header on follow-up", "", "login", "errors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			handler := newHandler()
+
+			r, w := newInvalidFormRequest()
+			if tt.postBag != "" {
+				r.Header.Set(inertiaheader.HeaderXInertiaErrorBag, tt.postBag)
+			}
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, http.StatusSeeOther, w.Code)
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			// act
+			r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{
+				Inertia:  true,
+				ErrorBag: tt.getBag,
+			})
+			r.AddCookie(cookies[0])
+			handler.ServeHTTP(w, r)
+
+			// assert
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var page struct {
+				Props map[string]any `json:"props"`
+			}
+
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+			if tt.expectKey == "errors" {
+				assert.Equal(t, map[string]any{"name": "Name is required"}, page.Props["errors"])
+			} else {
+				assert.Equal(t, map[string]any{"errors": map[string]any{"name": "Name is required"}},
+					page.Props[tt.expectKey])
+			}
+		})
+	}
+}
+
+func TestRequestPartialProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/users"},
+		execute: func(_ context.Context, r *Request[struct{}]) (Response, error) {
+			props := inertia.Props{inertia.NewProp("users", 1, nil)}
+			if slices.Contains(r.PartialProps("Users"), "stats") {
+				props = append(props, inertia.NewOptional("stats", inertia.LazyFunc(
+					func(context.Context) (any, error) { return "val-stats", nil })))
+			}
+
+			return NewResponse("Users", props), nil
+		},
+	}, nil)
+
+	r, w := inertiatest.NewRequest(http.MethodGet, "/users", &inertiatest.RequestConfig{
+		Inertia:          true,
+		PartialComponent: "Users",
+		Whitelist:        []string{"stats"},
+	})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Props map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "val-stats", page.Props["stats"])
+	assert.NotContains(t, page.Props, "users")
+}
+
+func TestWithPropsAccumulates(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/home"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Home", inertia.Props{inertia.NewProp("flash", "page", nil)}), nil
+		},
+	}, nil)
+
+	withAuth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, WithProps(r, inertia.Props{
+				inertia.NewProp("user", "alice", nil),
+				inertia.NewProp("flags", "v1", nil),
+			}))
+		})
+	}
+	withFlags := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, WithProps(r, inertia.Props{
+				inertia.NewProp("flags", "v2", nil),
+				inertia.NewProp("flash", "shared", nil),
+			}))
+		})
+	}
+
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(withAuth(withFlags(mux)))
+	r, w := inertiatest.NewRequest(http.MethodGet, "/home", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Props map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "alice", page.Props["user"])
+	assert.Equal(t, "v2", page.Props["flags"])
+	assert.Equal(t, "page", page.Props["flash"])
+}
+
+func TestRedirectBackOr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses Referer header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodPost, "/form", nil)
+		r.Header.Set(inertiaheader.HeaderReferer, "/form")
+
+		// act
+		RedirectBackOr(w, r, "/dashboard")
+
+		// assert
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/form", w.Header().Get("Location"))
+	})
+
+	t.Run("uses fallback without Referer", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodPost, "/form", nil)
+
+		// act
+		RedirectBackOr(w, r, "/dashboard")
+
+		// assert
+		assert.Equal(t, "/dashboard", w.Header().Get("Location"))
+	})
+
+	t.Run("uses default target without Referer", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodPost, "/form", nil)
+
+		// act
+		RedirectBack(w, r)
+
+		// assert
+		assert.Equal(t, DefaultRedirectBackTarget, w.Header().Get("Location"))
+	})
+
+	t.Run("response uses fallback", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodPost, "/form", nil)
+
+		// act
+		require.NoError(t, NewRedirectBackOrResponse("/dashboard").(RawResponseWriter).Write(w, r))
+
+		// assert
+		assert.Equal(t, "/dashboard", w.Header().Get("Location"))
+	})
+}
+
+func TestRedirectBackSessionPath(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+	u := &url.URL{Scheme: "http", Host: "example.com", Path: "/"}
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	visit := func(r *http.Request, w *httptest.ResponseRecorder) {
+		for _, c := range jar.Cookies(u) {
+			r.AddCookie(c)
+		}
+
+		handler.ServeHTTP(w, r)
+		jar.SetCookies(u, w.Result().Cookies())
+	}
+
+	// act: load the form page, then submit an invalid form without Referer
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form?step=2", nil)
+	visit(r, w)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	r, w = newInvalidFormRequest()
+	r.Header.Del(inertiaheader.HeaderReferer)
+	visit(r, w)
+
+	// assert: redirected to the visited page
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/form?step=2", w.Header().Get("Location"))
+
+	// act: follow the redirect and submit again
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form?step=2", &inertiatest.RequestConfig{Inertia: true})
+	visit(r, w)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]string{"name": "Name is required"}, pageErrors(t, w.Body.Bytes()))
+
+	r, w = newInvalidFormRequest()
+	r.Header.Del(inertiaheader.HeaderReferer)
+	visit(r, w)
+
+	// assert: the path survives consuming the flashed errors
+	assert.Equal(t, "/form?step=2", w.Header().Get("Location"))
+}
+
+func TestRedirectBackSessionPathSkipsInertiaVisits(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert: Inertia visits send the Referer header, the path isn't recorded
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func TestRedirectBackSessionPathAfterSessionMaxAge(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+
+	// act: load the form page
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form", nil)
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// assert: only the path cookie is written, and it outlives the session
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, PathCookieName, cookies[0].Name)
+	assert.Equal(t, DefaultPathMaxAge, cookies[0].MaxAge)
+
+	// act: submit an invalid form without Referer once the session MaxAge has passed
+	r, w = newInvalidFormRequest()
+	r.Header.Del(inertiaheader.HeaderReferer)
+
+	expiry := time.Now().Add(DefaultSessionMaxAge * time.Second)
+	for _, c := range cookies {
+		if c.Expires.After(expiry) {
+			r.AddCookie(c)
+		}
+	}
+
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/form", w.Header().Get("Location"))
+}
+
+func TestRedirectBackSessionPathFromFullPageVisit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newFlashTestHandler()
+
+	// act: load the form page without Inertia, then submit without Referer
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form", nil)
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	r, w = newInvalidFormRequest()
+	r.Header.Del(inertiaheader.HeaderReferer)
+	r.AddCookie(cookies[0])
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/form", w.Header().Get("Location"))
+}
+
+func TestResponseOptionsHeaders(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/account"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Account", inertia.Props{}, func(opts *ResponseOptions) {
+				opts.Headers = http.Header{
+					"Cache-Control":                 {"no-store"},
+					"x-custom":                      {"value"},
+					"Vary":                          {"Cookie"},
+					inertiaheader.HeaderXInertia:    {"false"},
+					inertiaheader.HeaderContentType: {"text/plain"},
+				}
+			}), nil
+		},
+	}, nil)
+
+	r, w := inertiatest.NewRequest(http.MethodGet, "/account", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "value", w.Header().Get("X-Custom"))
+	assert.Equal(t, []string{inertiaheader.HeaderXInertia, "Cookie"}, w.Header().Values(inertiaheader.HeaderVary))
+	assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+	assert.Equal(t, inertiaheader.ContentTypeJSON, w.Header().Get(inertiaheader.HeaderContentType))
+}
+
+func TestResponseStrictProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, &inertia.Config{StrictProps: true}))(mux)
+
+	var err error
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/users"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Users", inertia.Props{
+				inertia.NewProp("users", []string{"alice"}, nil),
+				inertia.NewProp("users", []string{"bob"}, nil),
+			}), nil
+		},
+	}, &MountOpts[struct{}]{
+		ErrorHandler: httphandler.ErrorHandlerFunc(func(w http.ResponseWriter, _ *http.Request, e error) {
+			err = e
+
+			w.WriteHeader(http.StatusInternalServerError)
+		}),
+	})
+
+	r, w := inertiatest.NewRequest(http.MethodGet, "/users", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.ErrorIs(t, err, inertia.ErrDuplicatePropKey)
+	assert.ErrorContains(t, err, "users")
+}
+
+func TestResponseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/public"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Public", inertia.Props{}, CacheFor(5*time.Minute)), nil
+		},
+	}, nil)
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/private"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Private", inertia.Props{}, NoStore()), nil
+		},
+	}, nil)
+
+	//nolint:exhaustruct
+	visited := &http.Cookie{Name: PathCookieName, Value: base64.RawURLEncoding.EncodeToString([]byte("/public"))}
+
+	tests := []struct {
+		name     string
+		path     string
+		config   *inertiatest.RequestConfig
+		cookie   *http.Cookie
+		expected string
+	}{
+		{"cached page HTML", "/public", nil, visited, "public, max-age=300"},
+		{"cached page HTML without recorded path", "/public", nil, nil, "public, max-age=300"},
+		{"cached page Inertia", "/public", &inertiatest.RequestConfig{Inertia: true}, nil, "no-store"},
+		{"no-store page HTML", "/private", nil, nil, "no-store"},
+		{"no-store page Inertia", "/private", &inertiatest.RequestConfig{Inertia: true}, nil, "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, w := inertiatest.NewRequest(http.MethodGet, tt.path, tt.config)
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+
+			// act
+			handler.ServeHTTP(w, r)
+
+			// assert
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, w.Header().Get("Cache-Control"))
+			assert.Equal(t, []string{inertiaheader.HeaderXInertia}, w.Header().Values(inertiaheader.HeaderVary))
+		})
+	}
+
+	t.Run("cached page sets no cookies", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/public", nil)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Result().Cookies())
+	})
+}
+
+func TestMountCORS(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+	config := &CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+
+	MountCORS(mux, "/users", config)
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/users"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Users", inertia.Props{}), nil
+		},
+	}, &MountOpts[struct{}]{CORS: config})
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodPost, Path: "/users"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewRedirectBackResponse(), nil
+		},
+	}, &MountOpts[struct{}]{CORS: config})
+
+	t.Run("preflight request", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodOptions, "/users", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "X-Inertia")
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), inertiaheader.HeaderXInertia)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("preflight request from disallowed origin", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodOptions, "/users", nil)
+		r.Header.Set("Origin", "https://evil.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("actual request", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/users", &inertiatest.RequestConfig{Inertia: true})
+		r.Header.Set("Origin", "https://app.example.com")
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), inertiaheader.HeaderXInertiaLocation)
+		assert.Contains(t, w.Header().Values(inertiaheader.HeaderVary), "Origin")
+	})
+
+	t.Run("preflight request with default methods", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		mux := http.NewServeMux()
+		MountCORS(mux, "/posts", &CORSConfig{AllowedOrigins: []string{"*"}})
+
+		r, w := inertiatest.NewRequest(http.MethodOptions, "/posts", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		// act
+		mux.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("credentials with any origin are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		config := &CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+
+		// act & assert
+		assert.Panics(t, func() { MountCORS(http.NewServeMux(), "/posts", config) })
+		assert.Panics(t, func() {
+			Mount(http.NewServeMux(), &testEndpoint[struct{}]{
+				meta: Meta{Method: http.MethodGet, Path: "/posts"},
+			}, &MountOpts[struct{}]{CORS: config})
+		})
+	})
+}
+
+// newEchoHandler mounts a POST endpoint rendering the decoded message name.
+func newEchoHandler(opts *MountOpts[testMessage]) http.Handler {
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/echo"},
+		execute: func(_ context.Context, r *Request[testMessage]) (Response, error) {
+			return NewResponse("Echo", inertia.Props{inertia.NewProp("name", r.Message.Name, nil)}), nil
+		},
+	}, opts)
+
+	return handler
+}
+
+// gzipString compresses s with gzip.
+func gzipString(t *testing.T, s string) io.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return &buf
+}
+
+func TestGzipRequestBody(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	handler := newEchoHandler(&MountOpts[testMessage]{MaxDecompressedBodySize: 64})
+
+	newRequest := func(t *testing.T, body string) (*http.Request, *httptest.ResponseRecorder) {
+		t.Helper()
+
+		r, w := inertiatest.NewRequest(http.MethodPost, "/echo", &inertiatest.RequestConfig{Inertia: true})
+		r.Body = io.NopCloser(gzipString(t, body))
+		r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+		r.Header.Set("Content-Encoding", "gzip")
+
+		return r, w
+	}
+
+	t.Run("decodes gzip-encoded JSON", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := newRequest(t, `{"name":"alice"}`)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page struct {
+			Props map[string]any `json:"props"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "alice", page.Props["name"])
+	})
+
+	t.Run("rejects body exceeding the limit", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := newRequest(t, `{"name":"`+strings.Repeat("a", 1024)+`"}`)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), ErrBodyTooLarge.Error())
+	})
+	t.Run("negative limit disables the limit", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		handler := newEchoHandler(&MountOpts[testMessage]{MaxDecompressedBodySize: -1})
+		name := strings.Repeat("a", 1024)
+		r, w := newRequest(t, `{"name":"`+name+`"}`)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page struct {
+			Props map[string]any `json:"props"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, name, page.Props["name"])
+	})
+}
+
+func TestRequestMediaType(t *testing.T) {
+	t.Parallel()
+
+	handler := newEchoHandler(nil)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		expected    string
+	}{
+		{"JSON with charset", "application/json; charset=utf-8", `{"name":"alice"}`, http.StatusOK, "alice"},
+		{"vendor JSON", "application/vnd.api+json", `{"name":"bob"}`, http.StatusOK, "bob"},
+		{"form", "application/x-www-form-urlencoded", `Name=carol`, http.StatusOK, "carol"},
+		{"unsupported", "application/xml", `<name>dave</name>`, http.StatusUnsupportedMediaType, ""},
+		{"plain text", "text/plain", `erin`, http.StatusUnsupportedMediaType, ""},
+		{"missing Content-Type", "", `{"name":"frank"}`, http.StatusUnsupportedMediaType, ""},
+		{"missing Content-Type without body", "", ``, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			r, w := inertiatest.NewRequest(http.MethodPost, "/echo", &inertiatest.RequestConfig{Inertia: true})
+			r.Body = io.NopCloser(strings.NewReader(tt.body))
+			r.ContentLength = int64(len(tt.body))
+
+			if tt.contentType != "" {
+				r.Header.Set(inertiaheader.HeaderContentType, tt.contentType)
+			}
+
+			// act
+			handler.ServeHTTP(w, r)
+
+			// assert
+			require.Equal(t, tt.status, w.Code)
+
+			if tt.status != http.StatusOK {
+				assert.Contains(t, w.Body.String(), ErrUnsupportedMediaType.Error())
+				return
+			}
+
+			var page struct {
+				Props map[string]any `json:"props"`
+			}
+
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			assert.Equal(t, tt.expected, page.Props["name"])
+		})
+	}
+}
+
+func TestQueryParameters(t *testing.T) {
+	t.Parallel()
+
+	type searchMessage struct {
+		Q    string   `form:"q"`
+		Page int      `form:"page"`
+		Tags []string `form:"tag"`
+	}
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[searchMessage]{
+		meta: Meta{Method: http.MethodGet, Path: "/search"},
+		execute: func(_ context.Context, r *Request[searchMessage]) (Response, error) {
+			return NewResponse("Search", inertia.Props{
+				inertia.NewProp("q", r.Message.Q, nil),
+				inertia.NewProp("page", r.Message.Page, nil),
+				inertia.NewProp("tags", r.Message.Tags, nil),
+			}), nil
+		},
+	}, nil)
+
+	r, w := inertiatest.NewRequest(http.MethodGet, "/search?q=foo&page=2&tag=a&tag=b", &inertiatest.RequestConfig{
+		Inertia: true,
+	})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Props map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "foo", page.Props["q"])
+	assert.InDelta(t, 2.0, page.Props["page"], 0)
+	assert.Equal(t, []any{"a", "b"}, page.Props["tags"])
+}
+
+func TestPathParameters(t *testing.T) {
+	t.Parallel()
+
+	type userMessage struct {
+		ID   int    `inertia-path:"id"`
+		Tab  string `inertia-path:"tab"`
+		Name string `json:"name"`
+	}
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	Mount(mux, &testEndpoint[userMessage]{
+		meta: Meta{Method: http.MethodGet, Path: "/users/{id}/{tab}"},
+		execute: func(_ context.Context, r *Request[userMessage]) (Response, error) {
+			return NewResponse("User", inertia.Props{
+				inertia.NewProp("id", r.Message.ID, nil),
+				inertia.NewProp("tab", r.Message.Tab, nil),
+			}), nil
+		},
+	}, nil)
+
+	t.Run("decodes path values", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/users/42/posts", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page struct {
+			Props map[string]any `json:"props"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.InDelta(t, 42.0, page.Props["id"], 0)
+		assert.Equal(t, "posts", page.Props["tab"])
+	})
+
+	t.Run("invalid value is a validation error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/users/abc/posts", &inertiatest.RequestConfig{Inertia: true})
+		r.Header.Set(inertiaheader.HeaderReferer, "/users")
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert: the errors are flashed and the client is redirected back
+		require.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/users", w.Header().Get("Location"))
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+
+		next := httptest.NewRequest(http.MethodGet, "/users", nil)
+		next.AddCookie(cookies[0])
+
+		sess, err := sessionFromRequest(next)
+		require.NoError(t, err)
+
+		errs := sess.ValidationErrors()
+		require.Len(t, errs, 1)
+		assert.Equal(t, "id", errs[0].Field())
+	})
+}
+
+func TestDecodePathValues_UnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var msg struct {
+		IDs []int `inertia-path:"ids"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("ids", "1")
+
+	// act
+	err := decodePathValues(r, &msg)
+
+	// assert
+	require.ErrorIs(t, err, errUnsupportedPathType)
+}
+
+func TestNewEndpointHandler(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	h := NewEndpointHandler(&testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/echo"},
+		execute: func(_ context.Context, r *Request[testMessage]) (Response, error) {
+			return NewResponse("Echo", inertia.Props{inertia.NewProp("name", r.Message.Name, nil)}), nil
+		},
+	}, nil)
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(h)
+
+	r, w := inertiatest.NewRequest(http.MethodPost, "/echo", &inertiatest.RequestConfig{Inertia: true})
+	r.Body = io.NopCloser(strings.NewReader(`{"name":"alice"}`))
+	r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Component string         `json:"component"`
+		Props     map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "Echo", page.Component)
+	assert.Equal(t, "alice", page.Props["name"])
+}
+
+type preExecuteEndpoint struct {
+	testEndpoint[testMessage]
+
+	preExecute func(context.Context, *http.Request) (Response, error)
+}
+
+func (e *preExecuteEndpoint) PreExecute(ctx context.Context, r *http.Request) (Response, error) {
+	return e.preExecute(ctx, r)
+}
+
+// readTrackingBody records whether the request body was read.
+type readTrackingBody struct {
+	io.Reader
+
+	read bool
+}
+
+func (b *readTrackingBody) Read(p []byte) (int, error) {
+	b.read = true
+	return b.Reader.Read(p) //nolint:wrapcheck
+}
+
+func (b *readTrackingBody) Close() error { return nil }
+
+func TestPreExecutor(t *testing.T) {
+	t.Parallel()
+
+	newEndpoint := func(enabled bool, executed *bool) *preExecuteEndpoint {
+		return &preExecuteEndpoint{
+			testEndpoint: testEndpoint[testMessage]{
+				meta: Meta{Method: http.MethodPost, Path: "/feature"},
+				execute: func(context.Context, *Request[testMessage]) (Response, error) {
+					*executed = true
+					return NewRedirectResponse("/done"), nil
+				},
+			},
+			preExecute: func(context.Context, *http.Request) (Response, error) {
+				if !enabled {
+					return NewRedirectResponse("/disabled"), nil
+				}
+
+				return nil, nil
+			},
+		}
+	}
+
+	newRequest := func() (*http.Request, *httptest.ResponseRecorder, *readTrackingBody) {
+		body := &readTrackingBody{Reader: strings.NewReader(`{"name":"alice"}`), read: false}
+		r, w := inertiatest.NewRequest(http.MethodPost, "/feature", &inertiatest.RequestConfig{Inertia: true})
+		r.Body = body
+		r.ContentLength = 16
+		r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+
+		return r, w, body
+	}
+
+	t.Run("short-circuits without reading the body", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var executed bool
+
+		h := NewEndpointHandler[testMessage](newEndpoint(false, &executed), nil)
+		r, w, body := newRequest()
+
+		// act
+		h.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/disabled", w.Header().Get("Location"))
+		assert.False(t, body.read)
+		assert.False(t, executed)
+	})
+
+	t.Run("continues when no response is returned", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var executed bool
+
+		h := NewEndpointHandler[testMessage](newEndpoint(true, &executed), nil)
+		r, w, body := newRequest()
+
+		// act
+		h.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, "/done", w.Header().Get("Location"))
+		assert.True(t, body.read)
+		assert.True(t, executed)
+	})
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	t.Parallel()
+
+	body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+
+	tests := []struct {
+		name         string
+		maxBodyBytes int64
+		status       int
+	}{
+		{"under the limit", 1024, http.StatusOK},
+		{"over the limit", 32, http.StatusRequestEntityTooLarge},
+		{"unlimited", -1, http.StatusOK},
+		{"default limit", 0, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			handler := newEchoHandler(&MountOpts[testMessage]{MaxBodyBytes: tt.maxBodyBytes})
+			r, w := inertiatest.NewRequest(http.MethodPost, "/echo", &inertiatest.RequestConfig{Inertia: true})
+			r.Body = io.NopCloser(strings.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Set(inertiaheader.HeaderContentType, "application/json")
+
+			// act
+			handler.ServeHTTP(w, r)
+
+			// assert
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestResponseOptionsStatus(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	h := NewEndpointHandler(&testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodPost, Path: "/users"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewResponse("Users/Show", inertia.Props{}, func(opts *ResponseOptions) {
+				opts.Status = http.StatusCreated
+			}), nil
+		},
+	}, nil)
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(h)
+
+	r, w := inertiatest.NewRequest(http.MethodPost, "/users", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+}
+
+func TestMountHead(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	var executed atomic.Bool
+
+	Mount(mux, &testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodGet, Path: "/status"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			executed.Store(true)
+			return NewResponse("Status", inertia.Props{inertia.NewProp("ok", true, nil)}, NoStore()), nil
+		},
+	}, nil)
+
+	tests := []struct {
+		name        string
+		config      *inertiatest.RequestConfig
+		contentType string
+	}{
+		{"HTML", nil, "text/html"},
+		{"Inertia", &inertiatest.RequestConfig{Inertia: true}, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			r, w := inertiatest.NewRequest(http.MethodHead, "/status", tt.config)
+
+			// act
+			handler.ServeHTTP(w, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Body.String())
+			assert.Equal(t, tt.contentType, w.Header().Get(inertiaheader.HeaderContentType))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.True(t, executed.Load())
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	t.Parallel()
+
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	errNotAllowed := errors.New("not allowed")
+
+	tests := []struct {
+		name         string
+		method       string
+		config       *inertiatest.RequestConfig
+		err          error
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:       "forbidden",
+			method:     http.MethodGet,
+			err:        StatusError(http.StatusForbidden, errNotAllowed),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "wrapped not found",
+			method:     http.MethodGet,
+			err:        fmt.Errorf("lookup: %w", StatusError(http.StatusNotFound, nil)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:         "Inertia visit",
+			method:       http.MethodGet,
+			config:       &inertiatest.RequestConfig{Inertia: true},
+			err:          StatusError(http.StatusNotFound, nil),
+			wantStatus:   http.StatusConflict,
+			wantLocation: "/users/1?tab=posts",
+		},
+		{
+			name:       "Inertia form submission",
+			method:     http.MethodPost,
+			config:     &inertiatest.RequestConfig{Inertia: true},
+			err:        StatusError(http.StatusForbidden, errNotAllowed),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			h := NewEndpointHandler(&testEndpoint[struct{}]{
+				meta: Meta{Method: tt.method, Path: "/users/1"},
+				execute: func(context.Context, *Request[struct{}]) (Response, error) {
+					return nil, tt.err
+				},
+			}, nil)
+			handler := inertia.NewMiddleware(inertia.New(tpl, nil))(h)
+
+			r, w := inertiatest.NewRequest(tt.method, "/users/1?tab=posts", tt.config)
+
+			// act
+			handler.ServeHTTP(w, r)
+
+			// assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantLocation, w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+		})
+	}
+
+	t.Run("errors.As", func(t *testing.T) {
+		t.Parallel()
+
+		var httpErr *HTTPError
+
+		err := fmt.Errorf("wrapped: %w", StatusError(http.StatusForbidden, errNotAllowed))
+
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Status)
+		require.ErrorIs(t, err, errNotAllowed)
+		assert.Equal(t, "Not Found", StatusError(http.StatusNotFound, nil).Error())
+	})
+}
+
+func TestFlashInput(t *testing.T) {
+	t.Parallel()
+
+	newHandler := func(flash bool) http.Handler {
+		tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+		mux := http.NewServeMux()
+		handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+		Mount(mux, &testEndpoint[testMessage]{
+			meta: Meta{Method: http.MethodGet, Path: "/form"},
+			execute: func(context.Context, *Request[testMessage]) (Response, error) {
+				return NewResponse("Form", inertia.Props{}), nil
+			},
+		}, nil)
+		Mount(mux, &testEndpoint[testMessage]{
+			meta: Meta{Method: http.MethodPost, Path: "/form"},
+			execute: func(context.Context, *Request[testMessage]) (Response, error) {
+				return NewRedirectBackResponse(), nil
+			},
+		}, &MountOpts[testMessage]{
+			Validator: ValidatorFunc[testMessage](func(testMessage) error {
+				return inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+			}),
+			SessionConfig: &SessionConfig{FlashInput: flash, FlashInputExclude: []string{"Password", "token"}},
+		})
+
+		return handler
+	}
+
+	oldInput := func(t *testing.T, body []byte) (map[string]any, bool) {
+		t.Helper()
+
+		var page struct {
+			Props map[string]any `json:"props"`
+		}
+
+		require.NoError(t, json.Unmarshal(body, &page))
+
+		old, ok := page.Props[OldInputPropKey].(map[string]any)
+
+		return old, ok
+	}
+
+	tests := []struct {
+		name        string
+		flash       bool
+		contentType string
+		body        string
+		want        map[string]any
+	}{
+		{
+			name:        "JSON",
+			flash:       true,
+			contentType: "application/json",
+			body:        `{"name":"","email":"alice@example.com","password":"secret","tags":["a","b"]}`,
+			want:        map[string]any{"name": "", "email": "alice@example.com", "tags": []any{"a", "b"}},
+		},
+		{
+			name:        "form",
+			flash:       true,
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Name=&email=alice%40example.com&token=t&tags=a&tags=b",
+			want:        map[string]any{"Name": "", "email": "alice@example.com", "tags": []any{"a", "b"}},
+		},
+		{
+			name:        "disabled",
+			flash:       false,
+			contentType: "application/json",
+			body:        `{"name":"","email":"alice@example.com"}`,
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			handler := newHandler(tt.flash)
+
+			r, w := inertiatest.NewRequest(http.MethodPost, "/form", &inertiatest.RequestConfig{Inertia: true})
+			r.Body = io.NopCloser(strings.NewReader(tt.body))
+			r.ContentLength = int64(len(tt.body))
+			r.Header.Set(inertiaheader.HeaderContentType, tt.contentType)
+			r.Header.Set(inertiaheader.HeaderReferer, "/form")
+
+			// act: submit an invalid form
+			handler.ServeHTTP(w, r)
+			require.Equal(t, http.StatusSeeOther, w.Code)
+			assert.Nil(t, inputFromRequest(r), "the caller's request should not be modified")
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			// act: follow the redirect
+			r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+			r.AddCookie(cookies[0])
+			handler.ServeHTTP(w, r)
+
+			// assert: the input is shared without the excluded fields
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, map[string]string{"name": "Name is required"}, pageErrors(t, w.Body.Bytes()))
+
+			old, ok := oldInput(t, w.Body.Bytes())
+			if tt.want == nil {
+				assert.False(t, ok, "old input should not be flashed")
+				return
+			}
+
+			require.True(t, ok, "old input should be flashed")
+			assert.Equal(t, tt.want, old)
+
+			// act: refresh the page
+			cookies = w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+			for _, c := range cookies {
+				r.AddCookie(c)
+			}
+
+			handler.ServeHTTP(w, r)
+
+			// assert: the old input is consumed
+			_, ok = oldInput(t, w.Body.Bytes())
+			assert.False(t, ok, "old input should be cleared after being read")
+		})
+	}
+}
+
+func TestNoContentResponse(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	h := NewEndpointHandler(&testEndpoint[struct{}]{
+		meta: Meta{Method: http.MethodPost, Path: "/notifications/1/read"},
+		execute: func(context.Context, *Request[struct{}]) (Response, error) {
+			return NewNoContentResponse(), nil
+		},
+	}, nil)
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(h)
+
+	r, w := inertiatest.NewRequest(http.MethodPost, "/notifications/1/read", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get(inertiaheader.HeaderXInertia))
+	assert.Empty(t, w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+	assert.Empty(t, w.Header().Get(inertiaheader.HeaderContentType))
+}
+
+func TestValidationErrorsFromContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+	mux := http.NewServeMux()
+	handler := inertia.NewMiddleware(inertia.New(tpl, nil))(mux)
+
+	type seen struct {
+		errors   []inertia.ValidationError
+		errorBag string
+	}
+
+	seenCh := make(chan seen, 2)
+
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodGet, Path: "/form"},
+		execute: func(ctx context.Context, _ *Request[testMessage]) (Response, error) {
+			seenCh <- seen{ValidationErrorsFromContext(ctx), ErrorBagFromContext(ctx)}
+
+			return NewResponse("Form", inertia.Props{}), nil
+		},
+	}, nil)
+	Mount(mux, &testEndpoint[testMessage]{
+		meta: Meta{Method: http.MethodPost, Path: "/form"},
+		execute: func(context.Context, *Request[testMessage]) (Response, error) {
+			return NewRedirectBackResponse(), nil
+		},
+	}, &MountOpts[testMessage]{
+		Validator: ValidatorFunc[testMessage](func(testMessage) error {
+			return inertia.ValidationErrors{inertia.NewValidationError("name", "Name is required")}
+		}),
+	})
+
+	// act: visit without flashed errors
+	r, w := inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	handler.ServeHTTP(w, r)
+
+	// assert
+	got := <-seenCh
+	assert.Empty(t, got.errors)
+	assert.Empty(t, got.errorBag)
+
+	// act: submit an invalid form and follow the redirect
+	r, w = newInvalidFormRequest()
+	r.Header.Set(inertiaheader.HeaderXInertiaErrorBag, "login")
+	handler.ServeHTTP(w, r)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	r, w = inertiatest.NewRequest(http.MethodGet, "/form", &inertiatest.RequestConfig{Inertia: true})
+	r.AddCookie(cookies[0])
+	handler.ServeHTTP(w, r)
+
+	// assert: the endpoint reads the flashed errors, which are still rendered
+	got = <-seenCh
+	require.Len(t, got.errors, 1)
+	assert.Equal(t, "name", got.errors[0].Field())
+	assert.Equal(t, "login", got.errorBag)
+
+	var page struct {
+		Props map[string]any `json:"props"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, map[string]any{"errors": map[string]any{"name": "Name is required"}}, page.Props["login"])
+}
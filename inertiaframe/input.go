@@ -0,0 +1,87 @@
+package inertiaframe
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
+)
+
+// OldInputPropKey is the key of the shared prop holding the input of the request
+// that failed validation, see SessionConfig.FlashInput.
+const OldInputPropKey = "old"
+
+// DefaultFlashInputExclude lists the input fields never flashed to the session by default.
+//
+//nolint:gochecknoglobals
+var DefaultFlashInputExclude = []string{"password", "password_confirmation", "current_password"}
+
+type inputCtx struct{}
+
+var kInputCtx = inputCtx{} //nolint:gochecknoglobals
+
+// captureBody makes the body of r be copied to the returned buffer as it is read.
+func captureBody(r *http.Request) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(r.Body, &buf), r.Body}
+
+	return &buf
+}
+
+// withInput returns a shallow copy of r with the decoded input of r, read from
+// its body captured in body, attached to the context, omitting the fields
+// listed in exclude.
+//
+// Only JSON objects and form data are attached, otherwise r is returned as is.
+func withInput(r *http.Request, body []byte, exclude []string) *http.Request {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(inertiaheader.HeaderContentType))
+
+	var input map[string]any
+
+	switch {
+	case isJSONMediaType(mediaType):
+		if err := json.Unmarshal(body, &input); err != nil {
+			d("failed to decode input to flash: %v", err)
+
+			return r
+		}
+	case r.PostForm != nil:
+		input = make(map[string]any, len(r.PostForm))
+
+		for key, values := range r.PostForm {
+			if len(values) == 1 {
+				input[key] = values[0]
+			} else {
+				input[key] = values
+			}
+		}
+	default:
+		return r
+	}
+
+	for key := range input {
+		if slices.ContainsFunc(exclude, func(s string) bool { return strings.EqualFold(s, key) }) {
+			delete(input, key)
+		}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), kInputCtx, input))
+}
+
+// inputFromRequest returns the input attached to r by withInput.
+func inputFromRequest(r *http.Request) map[string]any {
+	input, _ := r.Context().Value(kInputCtx).(map[string]any)
+
+	return input
+}
@@ -0,0 +1,112 @@
+package inertiaframe
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"go.segfaultmedaddy.com/inertia"
+)
+
+// TagPath is the struct tag populating message fields from path wildcards
+// of the endpoint's pattern, e.g., `inertia-path:"id"` for "/users/{id}".
+//
+// Supported field types are strings, booleans, integers, floats and
+// types implementing encoding.TextUnmarshaler.
+const TagPath = "inertia-path"
+
+var errUnsupportedPathType = errors.New("inertiaframe: unsupported path parameter type")
+
+//nolint:gochecknoglobals
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// decodePathValues populates the fields of msg tagged with TagPath from the path values of r.
+//
+// Values failing to convert to the field type are reported as inertia.ValidationErrors.
+func decodePathValues(r *http.Request, msg any) error {
+	val := reflect.ValueOf(msg).Elem()
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs inertia.ValidationErrors
+
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+
+		name := field.Tag.Get(TagPath)
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		raw := r.PathValue(name)
+		if raw == "" {
+			continue
+		}
+
+		if err := setPathValue(val.Field(i), raw); err != nil {
+			if errors.Is(err, errUnsupportedPathType) {
+				return fmt.Errorf("%w: field %s of type %s", err, field.Name, field.Type)
+			}
+
+			d("failed to decode path parameter %s: %v", name, err)
+
+			errs = append(errs, inertia.NewValidationError(name, fmt.Sprintf("invalid value %q", raw)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("inertiaframe: failed to decode path parameters: %w", errs)
+	}
+
+	return nil
+}
+
+// setPathValue converts raw to the type of v and stores it in v.
+func setPathValue(v reflect.Value, raw string) error {
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		//nolint:forcetypeassert,wrapcheck
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		v.SetFloat(f)
+	default:
+		return errUnsupportedPathType
+	}
+
+	return nil
+}
@@ -2,11 +2,15 @@ package inertiaframe
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,15 +19,134 @@ import (
 	"go.segfaultmedaddy.com/inertia"
 )
 
-type sessCtx struct{}
+type (
+	sessCtx       struct{}
+	sessConfigCtx struct{}
+)
 
-var kSessCtx = sessCtx{} //nolint:gochecknoglobals
+var (
+	kSessCtx       = sessCtx{}       //nolint:gochecknoglobals
+	kSessConfigCtx = sessConfigCtx{} //nolint:gochecknoglobals
+)
 
 const (
 	SessionCookieName = "_inertiaframe"
 	SessionPath       = "/"
+
+	// DefaultSessionMaxAge is the default session cookie lifetime in seconds.
+	// It is long enough for the session to survive a redirect.
+	DefaultSessionMaxAge = 60
+
+	// DefaultSessionMaxSize is the default maximum size of the session cookie value in bytes.
+	// It leaves room for the cookie name and attributes within the common 4KB browser limit.
+	DefaultSessionMaxSize = 4000
+
+	// PathCookieName is the name of the cookie holding the last visited path.
+	PathCookieName = "_inertiaframe_path"
+
+	// DefaultPathMaxAge is the default last visited path cookie lifetime in seconds.
+	// Unlike the flashed session data, the path is needed by RedirectBack
+	// for as long as the user stays on the page.
+	DefaultPathMaxAge = 24 * 60 * 60
 )
 
+// overflowPrefix marks a session cookie value holding a SessionStore ID
+// instead of the encoded session. It is not part of the base64 URL alphabet.
+const overflowPrefix = "~"
+
+// ErrSessionTooLarge is returned when the encoded session exceeds the configured
+// maximum size and no SessionStore is configured.
+var ErrSessionTooLarge = errors.New("inertiaframe: session exceeds maximum cookie size")
+
+// SessionStore stores sessions that don't fit into a cookie.
+//
+// When configured, oversized sessions are saved in the store and the cookie
+// holds only the session ID. Implementations are expected to expire entries
+// after the session MaxAge.
+type SessionStore interface {
+	// Save stores the encoded session data under the given ID.
+	Save(ctx context.Context, id string, data []byte) error
+
+	// Load returns the encoded session data stored under the given ID.
+	Load(ctx context.Context, id string) ([]byte, error)
+
+	// Delete removes the session data stored under the given ID.
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig configures the session cookie attributes.
+type SessionConfig struct {
+	// Domain is the cookie domain. Defaults to the host of the request.
+	Domain string
+
+	// Path is the cookie path. Defaults to SessionPath.
+	Path string
+
+	// MaxAge is the cookie lifetime in seconds. Defaults to DefaultSessionMaxAge.
+	MaxAge int
+
+	// PathMaxAge is the lifetime of the last visited path cookie in seconds.
+	// Defaults to DefaultPathMaxAge.
+	PathMaxAge int
+
+	// SameSite is the cookie SameSite attribute. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+
+	// Store holds sessions exceeding MaxSize. If nil, saving an oversized
+	// session fails with ErrSessionTooLarge.
+	Store SessionStore
+
+	// MaxSize is the maximum size of the session cookie value in bytes.
+	// Defaults to DefaultSessionMaxSize.
+	MaxSize int
+
+	// FlashInput stores the input of requests failing validation in the session,
+	// so that the page redirected back to receives it as the OldInputPropKey
+	// shared prop to repopulate its forms.
+	//
+	// Only JSON objects and form data are stored.
+	FlashInput bool
+
+	// FlashInputExclude lists the input fields, matched case-insensitively,
+	// never stored in the session, e.g., passwords.
+	// Defaults to DefaultFlashInputExclude.
+	FlashInputExclude []string
+
+	// Secure restricts the cookie to HTTPS connections.
+	Secure bool
+}
+
+func (c *SessionConfig) defaults() {
+	c.Path = cmp.Or(c.Path, SessionPath)
+	c.MaxAge = cmp.Or(c.MaxAge, DefaultSessionMaxAge)
+	c.PathMaxAge = cmp.Or(c.PathMaxAge, DefaultPathMaxAge)
+	c.MaxSize = cmp.Or(c.MaxSize, DefaultSessionMaxSize)
+	c.SameSite = cmp.Or(c.SameSite, http.SameSiteLaxMode)
+
+	if len(c.FlashInputExclude) == 0 {
+		c.FlashInputExclude = DefaultFlashInputExclude
+	}
+}
+
+// withSessionConfig attaches the session config to the request context.
+func withSessionConfig(r *http.Request, config *SessionConfig) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), kSessConfigCtx, config))
+}
+
+// sessionConfigFromRequest returns the session config attached to the request,
+// or the default config if none is set.
+func sessionConfigFromRequest(r *http.Request) *SessionConfig {
+	if config, ok := r.Context().Value(kSessConfigCtx).(*SessionConfig); ok {
+		return config
+	}
+
+	//nolint:exhaustruct
+	config := &SessionConfig{}
+	config.defaults()
+
+	return config
+}
+
 //nolint:gochecknoglobals
 var bufPool = sync.Pool{New: func() any { return bytes.NewBuffer(nil) }}
 
@@ -34,12 +157,19 @@ func init() {
 }
 
 // Session stores temporary flash data for the inertiaframe package.
-// It manages validation errors and the last visited path for redirect-back functionality.
+// It manages validation errors and the input of the failed request.
 // Session data is stored in a cookie and automatically cleared after being read.
 type session struct {
 	ErrorBag_         string                    //nolint:revive
-	Path_             string                    //nolint:revive
 	ValidationErrors_ []inertia.ValidationError //nolint:revive
+	OldInput_         []byte                    //nolint:revive // JSON-encoded
+
+	// storeID is the SessionStore ID if the session was loaded from the store.
+	storeID string
+
+	// stale reports whether the session cookie references a session that
+	// can't be loaded, so that the cookie must be cleared.
+	stale bool
 }
 
 // sessionFromRequest retrieves a session from the request. If the session
@@ -56,6 +186,30 @@ func sessionFromRequest(r *http.Request) (*session, error) {
 		return &session{}, nil
 	}
 
+	var storeID string
+
+	if id, ok := strings.CutPrefix(val, overflowPrefix); ok {
+		// The cookie is shared by all endpoints, including the ones mounted
+		// without a store, and the stored session may be expired. Start over
+		// with an empty session in both cases.
+		config := sessionConfigFromRequest(r)
+		if config.Store == nil {
+			d("session store is not configured, ignoring stored session %s", id)
+
+			return withSession(r, &session{stale: true}), nil //nolint:exhaustruct
+		}
+
+		b, err := config.Store.Load(r.Context(), id)
+		if err != nil {
+			d("failed to load session %s from store, ignoring it: %v", id, err)
+
+			return withSession(r, &session{stale: true}), nil //nolint:exhaustruct
+		}
+
+		storeID = id
+		val = string(b)
+	}
+
 	b, err := base64.RawURLEncoding.DecodeString(val)
 	if err != nil {
 		return nil, fmt.Errorf("inertiaframe: failed to decode session cookie: %w", err)
@@ -66,10 +220,41 @@ func sessionFromRequest(r *http.Request) (*session, error) {
 		return nil, fmt.Errorf("inertiaframe: failed to decode session: %w", err)
 	}
 
-	// Save session for future requests.
+	sess.storeID = storeID
+
+	return withSession(r, sess), nil
+}
+
+// withSession attaches sess to the context of r for future lookups and returns it.
+func withSession(r *http.Request, sess *session) *session {
 	*r = *r.WithContext(context.WithValue(r.Context(), kSessCtx, sess))
 
-	return sess, nil
+	return sess
+}
+
+// ValidationErrorsFromContext returns the validation errors flashed by the previous
+// request, e.g., to branch on them in an endpoint, without consuming them.
+//
+// The session is loaded into the context of the requests handled by mounted endpoints.
+// It returns nil if no errors were flashed or they are already consumed by rendering.
+func ValidationErrorsFromContext(ctx context.Context) []inertia.ValidationError {
+	if sess, ok := ctx.Value(kSessCtx).(*session); ok && sess != nil {
+		return sess.ValidationErrors_
+	}
+
+	return nil
+}
+
+// ErrorBagFromContext returns the error bag of the validation errors flashed by
+// the previous request, see ValidationErrorsFromContext.
+//
+// It returns an empty string for the default error bag or if no errors were flashed.
+func ErrorBagFromContext(ctx context.Context) string {
+	if sess, ok := ctx.Value(kSessCtx).(*session); ok && sess != nil {
+		return sess.ErrorBag_
+	}
+
+	return ""
 }
 
 // ValidationErrors returns validation errors from the previous request.
@@ -90,17 +275,79 @@ func (s *session) ErrorBag() string {
 	return ret
 }
 
-// Referer returns the last visited path stored in the session.
+// OldInput returns the JSON-encoded input of the previous request that failed validation.
+// Automatically cleared after being read.
+func (s *session) OldInput() []byte {
+	ret := s.OldInput_
+	s.OldInput_ = nil
+
+	return ret
+}
+
+// pathFromRequest returns the last visited path stored in the path cookie,
+// or an empty string if there is none.
 // Used by RedirectBack to navigate to the previous page.
-func (s *session) Referer() string { return s.Path_ }
+func pathFromRequest(r *http.Request) string {
+	val := httpcookie.Get(r, PathCookieName)
+	if val == "" {
+		return ""
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(val)
+	if err != nil {
+		d("failed to decode path cookie: %v", err)
+
+		return ""
+	}
+
+	return string(b)
+}
+
+// savePath stores the last visited path in the path cookie sent to the client.
+//
+// The path is kept apart from the session, so that it outlives the flashed
+// data and the session cookie isn't written on every visit.
+func savePath(w http.ResponseWriter, r *http.Request, path string) {
+	config := sessionConfigFromRequest(r)
+
+	//nolint:exhaustruct
+	http.SetCookie(w, &http.Cookie{
+		Name:     PathCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString([]byte(path)),
+		Path:     config.Path,
+		Domain:   config.Domain,
+		MaxAge:   config.PathMaxAge,
+		Expires:  time.Now().Add(time.Duration(config.PathMaxAge) * time.Second),
+		HttpOnly: true,
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
+	})
+}
 
 // Clear deletes the session cookie from the client.
 func (s *session) Clear(w http.ResponseWriter, r *http.Request) {
-	httpcookie.Delete(w, r, SessionCookieName)
+	config := sessionConfigFromRequest(r)
+
+	if s.storeID != "" && config.Store != nil {
+		if err := config.Store.Delete(r.Context(), s.storeID); err != nil {
+			d("failed to delete session from store: %v", err)
+		}
+	}
+
+	//nolint:exhaustruct
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Path:     config.Path,
+		Domain:   config.Domain,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
+	})
 }
 
 // Save persists the session to a cookie sent to the client.
-func (s *session) Save(w http.ResponseWriter) error {
+func (s *session) Save(w http.ResponseWriter, r *http.Request) error {
 	buf := bufPool.Get().(*bytes.Buffer) //nolint:forcetypeassert
 
 	defer func() {
@@ -113,14 +360,46 @@ func (s *session) Save(w http.ResponseWriter) error {
 		return fmt.Errorf("inertiaframe: failed to encode session: %w", err)
 	}
 
+	config := sessionConfigFromRequest(r)
+	value := base64.RawURLEncoding.EncodeToString(buf.Bytes())
+
+	// Drop the previously stored session, it is replaced by the new one.
+	if s.storeID != "" && config.Store != nil {
+		if err := config.Store.Delete(r.Context(), s.storeID); err != nil {
+			d("failed to delete session from store: %v", err)
+		}
+
+		s.storeID = ""
+	}
+
+	if len(value) > config.MaxSize {
+		if config.Store == nil {
+			return ErrSessionTooLarge
+		}
+
+		id := rand.Text()
+		if err := config.Store.Save(r.Context(), id, []byte(value)); err != nil {
+			return fmt.Errorf("inertiaframe: failed to save session to store: %w", err)
+		}
+
+		s.storeID = id
+		value = overflowPrefix + id
+	}
+
+	// Expires is set along with MaxAge for clients that don't support the latter.
+	expires := time.Now().Add(time.Duration(config.MaxAge) * time.Second)
+
 	//nolint:exhaustruct
 	cookie := &http.Cookie{
 		Name:     SessionCookieName,
-		Value:    base64.RawURLEncoding.EncodeToString(buf.Bytes()),
-		Path:     SessionPath,
+		Value:    value,
+		Path:     config.Path,
+		Domain:   config.Domain,
+		MaxAge:   config.MaxAge,
+		Expires:  expires,
 		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Now(),
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
 	}
 
 	http.SetCookie(w, cookie)
@@ -0,0 +1,204 @@
+package inertiaframe
+
+import (
+	"reflect"
+	"strings"
+
+	"go.segfaultmedaddy.com/inertia"
+)
+
+var _ Validator[any] = (*StructValidator[any])(nil)
+
+// StructValidate validates struct fields based on their tags.
+//
+// It is satisfied by *validator.Validate from github.com/go-playground/validator/v10.
+type StructValidate interface {
+	// Struct validates s and returns a slice of FieldError on validation failure.
+	Struct(s any) error
+}
+
+// FieldError describes a single failed field validation.
+//
+// It is satisfied by validator.FieldError from github.com/go-playground/validator/v10.
+type FieldError interface {
+	error
+
+	// StructNamespace returns the field path using struct field names, e.g., "User.Address.City".
+	StructNamespace() string
+
+	// Tag returns the validation tag that failed, e.g., "required".
+	Tag() string
+
+	// Param returns the param of the failed validation tag, e.g., "8" for "min=8".
+	Param() string
+}
+
+// DefaultFieldTagNames are the struct tags used to name fields in validation errors.
+//
+//nolint:gochecknoglobals
+var DefaultFieldTagNames = []string{"json", "form"}
+
+// StructValidatorOptions configures a StructValidator.
+type StructValidatorOptions struct {
+	// Message returns the message for a failed field validation.
+	// Defaults to the FieldError's Error method.
+	Message func(FieldError) string
+
+	// TagNames are the struct tags consulted, in order, to name the fields in validation errors.
+	// Fields without a matching tag use the struct field name.
+	// Defaults to DefaultFieldTagNames.
+	TagNames []string
+}
+
+// StructValidator is a Validator validating messages with a StructValidate,
+// such as *validator.Validate from github.com/go-playground/validator/v10.
+//
+// Failed field validations are converted into inertia.ValidationErrors with field names
+// taken from the struct tags, so that they match the names used by the client.
+// Nested fields are joined with dots, e.g., "items.0.name".
+type StructValidator[M any] struct {
+	validate StructValidate
+	message  func(FieldError) string
+	tagNames []string
+}
+
+// NewStructValidator creates a StructValidator using v to validate messages.
+//
+// If opts is nil, default options are used.
+func NewStructValidator[M any](v StructValidate, opts *StructValidatorOptions) *StructValidator[M] {
+	if opts == nil {
+		//nolint:exhaustruct
+		opts = &StructValidatorOptions{}
+	}
+
+	message := opts.Message
+	if message == nil {
+		message = func(fe FieldError) string { return fe.Error() }
+	}
+
+	tagNames := opts.TagNames
+	if tagNames == nil {
+		tagNames = DefaultFieldTagNames
+	}
+
+	return &StructValidator[M]{v, message, tagNames}
+}
+
+// Validate validates m, returning inertia.ValidationErrors if any field fails validation.
+func (v *StructValidator[M]) Validate(m M) error {
+	err := v.validate.Struct(m)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs, ok := toFieldErrors(err)
+	if !ok {
+		//nolint:wrapcheck
+		return err
+	}
+
+	typ := reflect.TypeFor[M]()
+	errs := make(inertia.ValidationErrors, 0, len(fieldErrs))
+
+	for _, fe := range fieldErrs {
+		errs = append(errs, inertia.NewValidationError(
+			v.fieldName(typ, fe.StructNamespace()),
+			v.message(fe),
+		))
+	}
+
+	return errs
+}
+
+// fieldName maps a struct namespace, e.g., "Message.Items[0].Name",
+// to a field name built from struct tags, e.g., "items.0.name".
+func (v *StructValidator[M]) fieldName(typ reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+
+	// The first segment is the name of the validated struct.
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+
+	names := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		typ = indirectType(typ)
+
+		if typ != nil && typ.Kind() == reflect.Struct {
+			if field, ok := typ.FieldByName(name); ok {
+				name = v.tagName(field)
+				typ = field.Type
+			} else {
+				typ = nil
+			}
+		}
+
+		names = append(names, name)
+
+		// Keep indexes and keys of slices, arrays and maps as separate segments.
+		for index != "" {
+			var key string
+
+			key, index, _ = strings.Cut(index, "]")
+			index = strings.TrimPrefix(index, "[")
+
+			names = append(names, key)
+
+			if typ = indirectType(typ); typ != nil {
+				switch typ.Kind() { //nolint:exhaustive
+				case reflect.Slice, reflect.Array, reflect.Map:
+					typ = typ.Elem()
+				default:
+					typ = nil
+				}
+			}
+		}
+	}
+
+	return strings.Join(names, ".")
+}
+
+// tagName returns the field name from the first configured tag, or the struct field name.
+func (v *StructValidator[M]) tagName(field reflect.StructField) string {
+	for _, tag := range v.tagNames {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// indirectType dereferences pointer types.
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// toFieldErrors extracts field errors from err, which is expected to be
+// a slice of FieldError, such as validator.ValidationErrors.
+func toFieldErrors(err error) ([]FieldError, bool) {
+	val := reflect.ValueOf(err)
+	if val.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	fieldErrs := make([]FieldError, 0, val.Len())
+
+	for i := range val.Len() {
+		fe, ok := val.Index(i).Interface().(FieldError)
+		if !ok {
+			return nil, false
+		}
+
+		fieldErrs = append(fieldErrs, fe)
+	}
+
+	return fieldErrs, true
+}
@@ -0,0 +1,141 @@
+package inertiaframe
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia"
+)
+
+// fakeFieldError mimics validator.FieldError.
+type fakeFieldError struct {
+	namespace string
+	tag       string
+	param     string
+}
+
+func (e fakeFieldError) Error() string {
+	return fmt.Sprintf("Key: '%s' Error:Field validation failed on the '%s' tag", e.namespace, e.tag)
+}
+func (e fakeFieldError) StructNamespace() string { return e.namespace }
+func (e fakeFieldError) Tag() string             { return e.tag }
+func (e fakeFieldError) Param() string           { return e.param }
+
+// fakeValidationErrors mimics validator.ValidationErrors.
+type fakeValidationErrors []FieldError
+
+func (fakeValidationErrors) Error() string { return "validation failed" }
+
+type fakeValidate struct{ err error }
+
+func (v fakeValidate) Struct(any) error { return v.err }
+
+type signupAddress struct {
+	City string `json:"city"`
+}
+
+type signupItem struct {
+	Name string `form:"item_name"`
+}
+
+type signupMessage struct {
+	Address  *signupAddress `json:"address"`
+	Email    string         `json:"email,omitempty"`
+	Password string         `form:"password"`
+	Nickname string         `json:"-"`
+	Items    []signupItem   `json:"items"`
+}
+
+func TestStructValidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("converts multiple field errors", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		v := NewStructValidator[signupMessage](fakeValidate{fakeValidationErrors{
+			fakeFieldError{namespace: "signupMessage.Email", tag: "required"},
+			fakeFieldError{namespace: "signupMessage.Password", tag: "min", param: "8"},
+			fakeFieldError{namespace: "signupMessage.Nickname", tag: "alphanum"},
+			fakeFieldError{namespace: "signupMessage.Address.City", tag: "required"},
+			fakeFieldError{namespace: "signupMessage.Items[1].Name", tag: "required"},
+		}}, nil)
+
+		// act
+		err := v.Validate(signupMessage{})
+
+		// assert
+		var errs inertia.ValidationErrors
+
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 5)
+
+		fields := make([]string, 0, len(errs))
+		for _, e := range errs {
+			fields = append(fields, e.Field())
+		}
+
+		assert.Equal(t, []string{"email", "password", "Nickname", "address.city", "items.1.item_name"}, fields)
+		assert.Equal(t, "Key: 'signupMessage.Email' Error:Field validation failed on the 'required' tag",
+			errs[0].Error())
+	})
+
+	t.Run("uses custom tag names and messages", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		v := NewStructValidator[*signupMessage](fakeValidate{fakeValidationErrors{
+			fakeFieldError{namespace: "signupMessage.Email", tag: "required"},
+			fakeFieldError{namespace: "signupMessage.Password", tag: "min", param: "8"},
+		}}, &StructValidatorOptions{
+			TagNames: []string{"form"},
+			Message: func(fe FieldError) string {
+				return fmt.Sprintf("%s:%s", fe.Tag(), fe.Param())
+			},
+		})
+
+		// act
+		err := v.Validate(&signupMessage{})
+
+		// assert
+		var errs inertia.ValidationErrors
+
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 2)
+		assert.Equal(t, "Email", errs[0].Field())
+		assert.Equal(t, "required:", errs[0].Error())
+		assert.Equal(t, "password", errs[1].Field())
+		assert.Equal(t, "min:8", errs[1].Error())
+	})
+
+	t.Run("passes through other errors", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errInvalid := errors.New("invalid validation")
+		v := NewStructValidator[signupMessage](fakeValidate{errInvalid}, nil)
+
+		// act
+		err := v.Validate(signupMessage{})
+
+		// assert
+		require.ErrorIs(t, err, errInvalid)
+	})
+
+	t.Run("returns nil on success", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		v := NewStructValidator[signupMessage](fakeValidate{nil}, nil)
+
+		// act
+		err := v.Validate(signupMessage{})
+
+		// assert
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+// Package inertiahead manages <title> and meta tags of Inertia pages.
+//
+// A Head accumulates head elements in a handler and is passed to the HTML template
+// via inertia.RenderContext.T, where the "inertiaHead" template function renders it.
+// Optionally, the head elements can be sent as a prop so the client can update
+// the document head on Inertia navigation.
+package inertiahead
+
+import (
+	"cmp"
+	"html/template"
+	"strings"
+
+	"go.segfaultmedaddy.com/inertia"
+)
+
+// DefaultPropKey is the default prop key used by Head.Prop.
+const DefaultPropKey = "head"
+
+// Header is implemented by template data types that carry a Head.
+type Header interface {
+	Head() *Head
+}
+
+// Meta is a single <meta> element.
+//
+// Either Name or Property is set, Property is used for Open Graph tags (og:*).
+type Meta struct {
+	Name     string `json:"name,omitempty"`
+	Property string `json:"property,omitempty"`
+	Content  string `json:"content"`
+}
+
+// Head accumulates head elements of a page.
+//
+// The zero value is an empty head ready to use.
+type Head struct {
+	Title_ string `json:"title,omitempty"` //nolint:revive
+	Meta_  []Meta `json:"meta,omitempty"`  //nolint:revive
+}
+
+// SetTitle sets the page title.
+func (h *Head) SetTitle(title string) { h.Title_ = title }
+
+// AddMeta adds a <meta name="..." content="..."> element, e.g., description.
+func (h *Head) AddMeta(name, content string) {
+	h.Meta_ = append(h.Meta_, Meta{Name: name, Property: "", Content: content})
+}
+
+// AddProperty adds a <meta property="..." content="..."> element, e.g., og:title.
+func (h *Head) AddProperty(property, content string) {
+	h.Meta_ = append(h.Meta_, Meta{Name: "", Property: property, Content: content})
+}
+
+// Head implements Header.
+func (h *Head) Head() *Head { return h }
+
+// HTML renders the head elements with all values escaped.
+func (h *Head) HTML() template.HTML {
+	if h == nil {
+		return ""
+	}
+
+	var w strings.Builder
+
+	if h.Title_ != "" {
+		w.WriteString("<title>")
+		w.WriteString(template.HTMLEscapeString(h.Title_))
+		w.WriteString("</title>")
+	}
+
+	for _, m := range h.Meta_ {
+		w.WriteString("<meta ")
+
+		if m.Property != "" {
+			w.WriteString(`property="`)
+			w.WriteString(template.HTMLEscapeString(m.Property))
+		} else {
+			w.WriteString(`name="`)
+			w.WriteString(template.HTMLEscapeString(m.Name))
+		}
+
+		w.WriteString(`" content="`)
+		w.WriteString(template.HTMLEscapeString(m.Content))
+		w.WriteString(`" />`)
+	}
+
+	//nolint:gosec
+	return template.HTML(w.String())
+}
+
+// Prop returns an always-included prop carrying the head elements, so the client
+// can update the document head on Inertia navigation.
+//
+// If key is empty, DefaultPropKey is used.
+func (h *Head) Prop(key string) inertia.Prop {
+	return inertia.NewAlways(cmp.Or(key, DefaultPropKey), h)
+}
+
+// WithHead attaches h to the HTML template data.
+func WithHead(h *Head) inertia.Option {
+	return inertia.WithTemplateData(h)
+}
+
+// FuncMap returns template functions for rendering the page head:
+//
+//	{{ inertiaHead .T }}
+//
+// The argument must be a *Head or implement Header, otherwise nothing is rendered.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{"inertiaHead": render}
+}
+
+func render(v any) template.HTML {
+	if h, ok := v.(Header); ok {
+		return h.Head().HTML()
+	}
+
+	return ""
+}
@@ -0,0 +1,116 @@
+package inertiahead
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia"
+	"go.segfaultmedaddy.com/inertia/internal/inertiatest"
+)
+
+func TestHead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("escapes title", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var h Head
+
+		h.SetTitle(`Tom & "Jerry" <script>`)
+
+		// act
+		html := h.HTML()
+
+		// assert
+		assert.Equal(t, template.HTML(`<title>Tom &amp; &#34;Jerry&#34; &lt;script&gt;</title>`), html)
+	})
+
+	t.Run("renders multiple meta tags", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var h Head
+
+		h.SetTitle("Home")
+		h.AddMeta("description", `A "quoted" description`)
+		h.AddProperty("og:title", "Home")
+		h.AddProperty("og:image", "https://example.com/a.png?x=1&y=2")
+
+		// act
+		html := string(h.HTML())
+
+		// assert
+		assert.Equal(t, `<title>Home</title>`+
+			`<meta name="description" content="A &#34;quoted&#34; description" />`+
+			`<meta property="og:title" content="Home" />`+
+			`<meta property="og:image" content="https://example.com/a.png?x=1&amp;y=2" />`, html)
+	})
+
+	t.Run("nil head renders nothing", func(t *testing.T) {
+		t.Parallel()
+
+		var h *Head
+
+		assert.Empty(t, h.HTML())
+	})
+}
+
+func TestFuncMap(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(
+		`<head>{{ inertiaHead .T }}</head><body>{{ .InertiaBody }}</body>`))
+	renderer := inertia.New(tpl, nil)
+
+	var h Head
+
+	h.SetTitle("Tom & Jerry")
+	h.AddMeta("description", "Cartoon")
+
+	t.Run("html response renders head", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "Home", inertia.NewRenderContext(WithHead(&h)))
+
+		// assert
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(),
+			`<head><title>Tom &amp; Jerry</title><meta name="description" content="Cartoon" /></head>`)
+	})
+
+	t.Run("json response carries head prop", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "Home", inertia.NewRenderContext(
+			WithHead(&h),
+			inertia.WithProps(h.Prop("")),
+		))
+
+		// assert
+		require.NoError(t, err)
+
+		var page struct {
+			Props struct {
+				Head Head `json:"head"`
+			} `json:"props"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, h, page.Props.Head)
+	})
+}
@@ -2,7 +2,11 @@ package inertiaprops
 
 import "go.segfaultmedaddy.com/inertia"
 
-var _ inertia.Proper = (*Map)(nil)
+var (
+	_ inertia.Proper = (*Map)(nil)
+	_ inertia.Proper = (*LazyMap)(nil)
+	_ inertia.Proper = (*deferredMap)(nil)
+)
 
 // Map is a convenient map-based Proper implementation for simple key-value props.
 // All values are treated as regular props (not lazy, deferred, or always).
@@ -21,3 +25,55 @@ func (m Map) Props() []inertia.Prop {
 }
 
 func (m Map) Len() int { return len(m) }
+
+// WithDeferred combines the regular props of m with deferred props from deferred.
+//
+// If opts is nil, default deferred options are used.
+func (m Map) WithDeferred(deferred LazyMap, opts *inertia.DeferredOptions) inertia.Props {
+	props := make(inertia.Props, 0, m.Len()+deferred.Len())
+	props = append(props, m.Props()...)
+	props = append(props, deferred.Deferred(opts).Props()...)
+
+	return props
+}
+
+// LazyMap is a map-based Proper implementation for lazily resolved props.
+// All values are treated as optional props, resolved only when explicitly requested.
+//
+// Use Deferred to create deferred props instead.
+type LazyMap map[string]inertia.Lazy
+
+func (m LazyMap) Props() []inertia.Prop {
+	props := make([]inertia.Prop, 0, len(m))
+	for k, v := range m {
+		props = append(props, inertia.NewOptional(k, v))
+	}
+
+	return props
+}
+
+func (m LazyMap) Len() int { return len(m) }
+
+// Deferred returns a Proper treating all values of m as deferred props
+// configured with opts.
+//
+// If opts is nil, default deferred options are used.
+func (m LazyMap) Deferred(opts *inertia.DeferredOptions) inertia.Proper {
+	return &deferredMap{m, opts}
+}
+
+type deferredMap struct {
+	m    LazyMap
+	opts *inertia.DeferredOptions
+}
+
+func (d *deferredMap) Props() []inertia.Prop {
+	props := make([]inertia.Prop, 0, len(d.m))
+	for k, v := range d.m {
+		props = append(props, inertia.NewDeferred(k, v, d.opts))
+	}
+
+	return props
+}
+
+func (d *deferredMap) Len() int { return len(d.m) }
@@ -0,0 +1,133 @@
+package inertiaprops
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia"
+	"go.segfaultmedaddy.com/inertia/internal/inertiatest"
+)
+
+//nolint:gochecknoglobals
+var tpl = template.Must(template.New("test").Parse(`{{ .InertiaBody }}`))
+
+type page struct {
+	Props         map[string]any      `json:"props"`
+	DeferredProps map[string][]string `json:"deferredProps"`
+}
+
+func render(t *testing.T, config *inertiatest.RequestConfig, propers ...inertia.Proper) page {
+	t.Helper()
+
+	renderer := inertia.New(tpl, nil)
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", config)
+
+	err := renderer.Render(w, req, "TestComponent", inertia.NewRenderContext(inertia.WithProps(propers...)))
+	require.NoError(t, err)
+
+	var p page
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+
+	return p
+}
+
+func lazyValue(v any) inertia.Lazy {
+	return inertia.LazyFunc(func(context.Context) (any, error) { return v, nil })
+}
+
+func TestLazyMap(t *testing.T) {
+	t.Parallel()
+
+	m := LazyMap{"a": lazyValue("val-a"), "b": lazyValue("val-b")}
+
+	t.Run("optional props are skipped on initial render", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		p := render(t, &inertiatest.RequestConfig{Inertia: true}, m)
+
+		// assert
+		assert.Equal(t, 2, m.Len())
+		assert.NotContains(t, p.Props, "a")
+		assert.NotContains(t, p.Props, "b")
+		assert.Empty(t, p.DeferredProps)
+	})
+
+	t.Run("optional props are resolved when requested", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		p := render(t, &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "TestComponent",
+			Whitelist:        []string{"a"},
+		}, m)
+
+		// assert
+		assert.Equal(t, "val-a", p.Props["a"])
+		assert.NotContains(t, p.Props, "b")
+	})
+}
+
+func TestMapWithDeferred(t *testing.T) {
+	t.Parallel()
+
+	props := Map{"title": "Title", "count": 2}.WithDeferred(
+		LazyMap{"stats": lazyValue("val-stats"), "feed": lazyValue("val-feed")},
+		&inertia.DeferredOptions{Group: "sidebar"},
+	)
+
+	t.Run("initial render", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		p := render(t, &inertiatest.RequestConfig{Inertia: true}, props)
+
+		// assert
+		assert.Equal(t, 4, props.Len())
+		assert.Equal(t, "Title", p.Props["title"])
+		assert.InDelta(t, 2.0, p.Props["count"], 0)
+		assert.NotContains(t, p.Props, "stats")
+		assert.NotContains(t, p.Props, "feed")
+		assert.ElementsMatch(t, []string{"stats", "feed"}, p.DeferredProps["sidebar"])
+	})
+
+	t.Run("deferred props are resolved when requested", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		p := render(t, &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "TestComponent",
+			Whitelist:        []string{"stats", "feed"},
+		}, props)
+
+		// assert
+		assert.Equal(t, "val-stats", p.Props["stats"])
+		assert.Equal(t, "val-feed", p.Props["feed"])
+	})
+}
+
+func TestWithProps(t *testing.T) {
+	t.Parallel()
+
+	// act
+	p := render(t, &inertiatest.RequestConfig{Inertia: true}, nil,
+		inertia.Props{inertia.NewProp("a", "val-a", nil), inertia.NewProp("b", "val-b", nil)},
+		Map{"c": "val-c"},
+		inertia.NewAlways("d", "val-d"),
+	)
+
+	// assert
+	assert.Equal(t, "val-a", p.Props["a"])
+	assert.Equal(t, "val-b", p.Props["b"])
+	assert.Equal(t, "val-c", p.Props["c"])
+	assert.Equal(t, "val-d", p.Props["d"])
+}
@@ -7,6 +7,18 @@ type Page struct {
 	URL            string              `json:"url"`
 	Version        string              `json:"version"`
 	MergeProps     []string            `json:"mergeProps,omitempty"`
+	PollInterval   int64               `json:"pollInterval,omitzero"` // milliseconds
 	EncryptHistory bool                `json:"encryptHistory"`
 	ClearHistory   bool                `json:"clearHistory"`
 }
+
+// Prop returns the value of the prop with key and whether the page has it.
+func (p *Page) Prop(key string) (any, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	v, ok := p.Props[key]
+
+	return v, ok
+}
@@ -10,9 +10,14 @@ const (
 	HeaderXInertiaReset            = "X-Inertia-Reset"             // client, force reload
 	HeaderXInertiaErrorBag         = "X-Inertia-Error-Bag"         // client
 
-	HeaderVary        = "Vary"
-	HeaderContentType = "Content-Type"
-	HeaderReferer     = "Referer"
+	HeaderVary               = "Vary"
+	HeaderContentType        = "Content-Type"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderContentLength      = "Content-Length"
+	HeaderReferer            = "Referer"
+	HeaderAcceptLanguage     = "Accept-Language"
+	HeaderCacheControl       = "Cache-Control"
+	HeaderCSP                = "Content-Security-Policy"
 )
 
 const (
@@ -2,8 +2,11 @@ package inertiassr
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/go-json-experiment/json"
@@ -26,17 +29,42 @@ type SSRClient interface {
 	Render(context.Context, *inertiabase.Page) (*SSRTemplateData, error)
 }
 
+// ErrResponseTooLarge is returned when the SSR response body exceeds Options.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("inertia: SSR response exceeds maximum size")
+
+// Options configures the HTTP SSR client.
+type Options struct {
+	// Client is the HTTP client used to make requests.
+	Client *http.Client
+
+	// MaxResponseBytes limits the size of the SSR response body.
+	// If 0 or negative, the size is not limited.
+	MaxResponseBytes int64
+
+	// Method is the HTTP method of render requests, sent to the client URL as is,
+	// e.g., http.MethodPost to "http://localhost:13714/render" for the Inertia.js
+	// SSR server. Defaults to http.MethodGet for backward compatibility.
+	Method string
+}
+
 // ssr is an HTTP client that makes requests to a server-side rendering service.
 type ssr struct {
-	client *http.Client
-	url    string
+	client           *http.Client
+	url              string
+	method           string
+	maxResponseBytes int64
 }
 
 func NewHTTPSsrClient(url string, client *http.Client) SSRClient {
+	return NewHTTPSsrClientWithOptions(url, &Options{Client: client, MaxResponseBytes: 0, Method: ""})
+}
+
+func NewHTTPSsrClientWithOptions(url string, opts *Options) SSRClient {
 	debug.Assert(url != "", "url must be provided")
-	debug.Assert(client != nil, "client must be provided")
+	debug.Assert(opts != nil, "opts must be provided")
+	debug.Assert(opts.Client != nil, "client must be provided")
 
-	return &ssr{client, url}
+	return &ssr{opts.Client, url, cmp.Or(opts.Method, http.MethodGet), opts.MaxResponseBytes}
 }
 
 func (s *ssr) Render(ctx context.Context, p *inertiabase.Page) (*SSRTemplateData, error) {
@@ -47,7 +75,7 @@ func (s *ssr) Render(ctx context.Context, p *inertiabase.Page) (*SSRTemplateData
 		return nil, fmt.Errorf("inertia: failed to marshal page: %w", err)
 	}
 
-	r, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, bytes.NewReader(b))
+	r, err := http.NewRequestWithContext(ctx, s.method, s.url, bytes.NewReader(b))
 	if err != nil {
 		return nil, fmt.Errorf("inertia: failed to create HTTP request: %w", err)
 	}
@@ -65,10 +93,92 @@ func (s *ssr) Render(ctx context.Context, p *inertiabase.Page) (*SSRTemplateData
 		return nil, fmt.Errorf("inertia: unexpected HTTP status code: %d", resp.StatusCode)
 	}
 
+	var (
+		body    io.Reader = resp.Body
+		limited *limitedReader
+	)
+
+	if s.maxResponseBytes > 0 {
+		limited = &limitedReader{r: resp.Body, n: s.maxResponseBytes, exceeded: false}
+		body = limited
+	}
+
 	var data SSRTemplateData
-	if err := json.UnmarshalRead(resp.Body, &data); err != nil {
+	if err := json.UnmarshalRead(body, &data); err != nil {
+		if limited != nil && limited.exceeded {
+			return nil, fmt.Errorf("%w (%d bytes)", ErrResponseTooLarge, s.maxResponseBytes)
+		}
+
 		return nil, fmt.Errorf("inertia: failed to decode JSON response: %w", err)
 	}
 
 	return &data, nil
 }
+
+// limitedReader reads from r, failing with ErrResponseTooLarge
+// once more than n bytes are read.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+
+	// Read one byte past the limit to detect oversized bodies.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	if l.n < 0 {
+		l.exceeded = true
+
+		return n, ErrResponseTooLarge
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// RenderFunc renders a page on the server.
+type RenderFunc func(context.Context, *inertiabase.Page) (*SSRTemplateData, error)
+
+// Handler returns an HTTP handler serving the SSR protocol expected by the HTTP SSR client.
+//
+// It decodes the page JSON from the request body, calls render and
+// writes the rendered head and body as JSON.
+func Handler(render RenderFunc) http.Handler {
+	debug.Assert(render != nil, "render must be provided")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page inertiabase.Page
+		if err := json.UnmarshalRead(r.Body, &page); err != nil {
+			http.Error(w, "inertia: failed to decode page", http.StatusBadRequest)
+
+			return
+		}
+
+		data, err := render(r.Context(), &page)
+		if err != nil {
+			http.Error(w, "inertia: failed to render page", http.StatusInternalServerError)
+
+			return
+		}
+
+		b, err := json.Marshal(data)
+		if err != nil {
+			http.Error(w, "inertia: failed to encode response", http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set(inertiaheader.HeaderContentType, inertiaheader.ContentTypeJSON)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	})
+}
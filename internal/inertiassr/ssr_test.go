@@ -1,10 +1,14 @@
 package inertiassr
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -95,4 +99,148 @@ func TestSsrRender(t *testing.T) {
 		_, err := client.Render(t.Context(), page)
 		assert.Error(t, err)
 	})
+
+	t.Run("uses configured method and path", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			method string
+			want   string
+		}{
+			{name: "default", method: "", want: http.MethodGet},
+			{name: "GET", method: http.MethodGet, want: http.MethodGet},
+			{name: "POST", method: http.MethodPost, want: http.MethodPost},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				expected := &SSRTemplateData{Head: "<title>Test</title>", Body: "<div>Content</div>"}
+
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, tt.want, r.Method)
+					assert.Equal(t, "/render", r.URL.Path)
+
+					buf, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					assert.JSONEq(t, string(pageJSON), string(buf))
+
+					w.Header().Set("Content-Type", "application/json")
+					assert.NoError(t, json.NewEncoder(w).Encode(expected))
+				}))
+				defer server.Close()
+
+				client := NewHTTPSsrClientWithOptions(server.URL+"/render", &Options{Client: defaultClient, Method: tt.method})
+				result, err := client.Render(t.Context(), page)
+
+				require.NoError(t, err)
+				assert.Equal(t, expected, result)
+			})
+		}
+	})
+
+	t.Run("limits response size", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			assert.NoError(t, json.NewEncoder(w).Encode(&SSRTemplateData{
+				Head: "<title>Test</title>",
+				Body: strings.Repeat("x", 1<<16),
+			}))
+		}))
+		defer server.Close()
+
+		client := NewHTTPSsrClientWithOptions(server.URL, &Options{Client: defaultClient, MaxResponseBytes: 1024})
+		_, err := client.Render(t.Context(), page)
+		require.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("allows response within size limit", func(t *testing.T) {
+		t.Parallel()
+
+		expected := &SSRTemplateData{Head: "<title>Test</title>", Body: "<div>Content</div>"}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			assert.NoError(t, json.NewEncoder(w).Encode(expected))
+		}))
+		defer server.Close()
+
+		client := NewHTTPSsrClientWithOptions(server.URL, &Options{Client: defaultClient, MaxResponseBytes: 1024})
+		result, err := client.Render(t.Context(), page)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	page := &inertiabase.Page{
+		Component: "Test",
+		Props:     map[string]any{"foo": "bar"},
+		URL:       "/test",
+		Version:   "1.0.0",
+	}
+
+	t.Run("round-trips with the client", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		server := httptest.NewServer(Handler(func(_ context.Context, p *inertiabase.Page) (*SSRTemplateData, error) {
+			return &SSRTemplateData{
+				Head: "<title>" + p.Component + "</title>",
+				Body: fmt.Sprintf(`<div id="app">%s %v</div>`, p.URL, p.Props["foo"]),
+			}, nil
+		}))
+		defer server.Close()
+
+		client := NewHTTPSsrClient(server.URL, defaultClient)
+
+		// act
+		result, err := client.Render(t.Context(), page)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "<title>Test</title>", result.Head)
+		assert.Equal(t, `<div id="app">/test bar</div>`, result.Body)
+	})
+
+	t.Run("render error fails the client", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		server := httptest.NewServer(Handler(func(context.Context, *inertiabase.Page) (*SSRTemplateData, error) {
+			return nil, errors.New("boom")
+		}))
+		defer server.Close()
+
+		client := NewHTTPSsrClient(server.URL, defaultClient)
+
+		// act
+		_, err := client.Render(t.Context(), page)
+
+		// assert
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid page", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", strings.NewReader("invalid json"))
+
+		// act
+		Handler(func(context.Context, *inertiabase.Page) (*SSRTemplateData, error) {
+			return &SSRTemplateData{}, nil
+		}).ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
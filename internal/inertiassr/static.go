@@ -0,0 +1,47 @@
+package inertiassr
+
+import (
+	"context"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiabase"
+)
+
+var (
+	_ SSRClient = (*staticClient)(nil)
+	_ SSRClient = funcClient(nil)
+)
+
+// staticClient is an SSRClient rendering every page with fixed head and body.
+type staticClient struct {
+	data SSRTemplateData
+}
+
+// NewStaticSSRClient creates an SSRClient rendering every page with the given head and body,
+// e.g., to test the server-side rendering path without an SSR service.
+func NewStaticSSRClient(head, body string) SSRClient {
+	return &staticClient{SSRTemplateData{Head: head, Body: body}}
+}
+
+func (c *staticClient) Render(context.Context, *inertiabase.Page) (*SSRTemplateData, error) {
+	data := c.data
+
+	return &data, nil
+}
+
+// funcClient is an SSRClient rendering pages with a function.
+type funcClient func(*inertiabase.Page) (SSRTemplateData, error)
+
+// NewFuncSSRClient creates an SSRClient rendering pages with fn,
+// e.g., to test the server-side rendering path with responses depending on the page.
+func NewFuncSSRClient(fn func(*inertiabase.Page) (SSRTemplateData, error)) SSRClient {
+	return funcClient(fn)
+}
+
+func (fn funcClient) Render(_ context.Context, p *inertiabase.Page) (*SSRTemplateData, error) {
+	data, err := fn(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
@@ -3,8 +3,12 @@ package inertia
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	runtimedebug "runtime/debug"
 	"slices"
+	"time"
 
 	"go.inout.gg/foundations/debug"
 	"go.inout.gg/foundations/must"
@@ -14,8 +18,13 @@ import (
 
 type ctxKey struct{}
 
+type sharedPropsCtxKey struct{}
+
 //nolint:gochecknoglobals
-var kCtxKey = ctxKey{}
+var (
+	kCtxKey            = ctxKey{}
+	kSharedPropsCtxKey = sharedPropsCtxKey{}
+)
 
 // https://inertiajs.com/redirects#303-response-code
 //
@@ -32,6 +41,13 @@ var DefaultVersionMismatchHandler = func(w http.ResponseWriter, r *http.Request)
 	Location(w, r, r.RequestURI)
 }
 
+// DefaultVersionExtractor reads the client asset version from the X-Inertia-Version header.
+//
+//nolint:gochecknoglobals
+var DefaultVersionExtractor = func(r *http.Request) string {
+	return r.Header.Get(inertiaheader.HeaderXInertiaVersion)
+}
+
 // MiddlewareConfig configures the behavior of the Inertia.js middleware.
 type MiddlewareConfig struct {
 	// EmptyResponseHandler is called when a handler produces no response body.
@@ -43,6 +59,30 @@ type MiddlewareConfig struct {
 	//
 	// If nil, defaults to redirecting the client to the current URL to reload the page with fresh assets.
 	VersionMismatchHandler http.HandlerFunc
+
+	// VersionExtractor returns the client's asset version of an Inertia request,
+	// compared to the server's to detect a mismatch.
+	//
+	// It is useful for clients sending the version other than in the
+	// X-Inertia-Version header, e.g., in a query parameter.
+	//
+	// If nil, defaults to reading the X-Inertia-Version header.
+	VersionExtractor func(*http.Request) string
+
+	// Skipper reports whether the middleware should be bypassed for a request.
+	//
+	// Skipped requests are passed straight to the next handler without the renderer
+	// injected into the context and without Inertia headers set.
+	// Useful for static assets, health checks and other non-Inertia routes.
+	//
+	// If nil, no requests are skipped.
+	Skipper func(*http.Request) bool
+
+	// Logger logs middleware events, such as asset version mismatches,
+	// with structured attributes.
+	//
+	// If nil, nothing is logged.
+	Logger *slog.Logger
 }
 
 func (m *MiddlewareConfig) defaults() {
@@ -54,8 +94,17 @@ func (m *MiddlewareConfig) defaults() {
 		m.VersionMismatchHandler = DefaultVersionMismatchHandler
 	}
 
+	if m.VersionExtractor == nil {
+		m.VersionExtractor = DefaultVersionExtractor
+	}
+
+	if m.Logger == nil {
+		m.Logger = discardLogger
+	}
+
 	debug.Assert(m.EmptyResponseHandler != nil, "EmptyResponseHandler must be set")
 	debug.Assert(m.VersionMismatchHandler != nil, "VersionMismatchHandler must be set")
+	debug.Assert(m.VersionExtractor != nil, "VersionExtractor must be set")
 }
 
 // NewMiddleware creates an HTTP middleware that enables Inertia.js protocol handling.
@@ -76,8 +125,13 @@ func NewMiddleware(renderer *Renderer, opts ...func(*MiddlewareConfig)) func(htt
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if config.Skipper != nil && config.Skipper(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			h := w.Header()
-			r = r.WithContext(context.WithValue(r.Context(), kCtxKey, renderer))
+			r = WithRenderer(r, renderer)
 
 			h.Set(inertiaheader.HeaderVary, inertiaheader.HeaderXInertia)
 
@@ -86,10 +140,15 @@ func NewMiddleware(renderer *Renderer, opts ...func(*MiddlewareConfig)) func(htt
 				return
 			}
 
-			clientVersion := r.Header.Get(inertiaheader.HeaderXInertiaVersion)
+			clientVersion := config.VersionExtractor(r)
 
 			serverVersion := renderer.Version()
 			if clientVersion != serverVersion {
+				config.Logger.InfoContext(r.Context(), "inertia: asset version mismatch",
+					slog.String("client_version", clientVersion),
+					slog.String("server_version", serverVersion),
+					slog.String("path", r.URL.Path))
+
 				config.VersionMismatchHandler(w, r)
 				return
 			}
@@ -112,6 +171,148 @@ func NewMiddleware(renderer *Renderer, opts ...func(*MiddlewareConfig)) func(htt
 	}
 }
 
+// NewRecoveryMiddleware creates an HTTP middleware recovering from panics in handlers.
+//
+// For Inertia requests, it renders component with renderer and a 500 status, passing
+// the status as the "status" prop, so that the SPA shows an error page.
+// Other requests receive a plain 500 response. Nothing is rendered if the handler
+// has already written the response. The panic and its stack are logged with
+// Config.Logger of renderer.
+//
+// It must be installed inside the Inertia middleware. Panics with http.ErrAbortHandler
+// are propagated to abort the response.
+func NewRecoveryMiddleware(renderer *Renderer, component string) func(http.Handler) http.Handler {
+	debug.Assert(renderer != nil, "renderer must be defined")
+	debug.Assert(component != "", "component must be non-empty")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				if rec == http.ErrAbortHandler { //nolint:errorlint,err113
+					panic(rec)
+				}
+
+				renderer.logger.ErrorContext(r.Context(), "inertia: recovered from panic",
+					slog.Any("panic", rec), slog.String("stack", string(runtimedebug.Stack())))
+
+				// The handler may have panicked halfway through writing the response,
+				// e.g., streaming a page, appending the error page would corrupt it.
+				if !responseEmpty(w) {
+					d("response is already written, skipping error page")
+					return
+				}
+
+				if !isInertiaRequest(r) {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+
+				err := renderer.Render(w, r, component, NewRenderContext(
+					WithStatus(http.StatusInternalServerError),
+					WithProps(NewAlways("status", http.StatusInternalServerError)),
+				))
+				if err != nil {
+					d("failed to render error page: %v", err)
+
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// responseEmpty reports whether nothing was written to w yet.
+// Writers not tracking their state, i.e., outside of the Inertia middleware,
+// are reported as empty.
+func responseEmpty(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case interface{ Empty() bool }:
+			return rw.Empty()
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return true
+		}
+	}
+}
+
+// WithRenderer returns a shallow copy of r with renderer attached to its context.
+// Render uses the renderer attached last, so downstream middleware can override
+// the renderer injected by NewMiddleware, e.g., to pick a tenant-specific template.
+//
+// Note that the asset version check is performed by NewMiddleware with its own renderer.
+func WithRenderer(r *http.Request, renderer *Renderer) *http.Request {
+	debug.Assert(renderer != nil, "renderer must be defined")
+
+	return r.WithContext(context.WithValue(r.Context(), kCtxKey, renderer))
+}
+
+var (
+	// ErrRendererNotFound is returned when no renderer is attached to the request context.
+	ErrRendererNotFound = errors.New(
+		"inertia: renderer not found in request context - did you forget to use the middleware?",
+	)
+
+	// ErrInvalidRenderer is returned when the value attached to the request context
+	// under the renderer key is not a valid renderer.
+	ErrInvalidRenderer = errors.New("inertia: invalid renderer in request context")
+)
+
+// RendererFromContext returns the renderer attached to ctx by NewMiddleware or WithRenderer.
+//
+// It returns ErrRendererNotFound if no renderer is attached, and ErrInvalidRenderer
+// if the attached value is not a non-nil *Renderer.
+func RendererFromContext(ctx context.Context) (*Renderer, error) {
+	val := ctx.Value(kCtxKey)
+	if val == nil {
+		return nil, ErrRendererNotFound
+	}
+
+	renderer, ok := val.(*Renderer)
+	if !ok || renderer == nil {
+		return nil, fmt.Errorf("%w: got %T", ErrInvalidRenderer, val)
+	}
+
+	return renderer, nil
+}
+
+// WithSharedProps returns a shallow copy of r with props attached to its context
+// as shared props, which the Renderer adds to every page rendered for the request.
+// Useful in middleware to provide global data (e.g., auth user, flash messages) to all pages.
+//
+// Multiple calls accumulate props, so that several middlewares can each contribute shared props.
+// Props added later take precedence over earlier ones, and render context props take precedence
+// over shared props when keys overlap.
+func WithSharedProps(r *http.Request, props Proper) *http.Request {
+	if props == nil {
+		return r
+	}
+
+	shared := SharedProps(r)
+
+	// Copy to not share the backing array with requests derived from r.
+	accumulated := make(Props, 0, len(shared)+props.Len())
+	accumulated = append(accumulated, shared...)
+	accumulated = append(accumulated, props.Props()...)
+
+	return r.WithContext(context.WithValue(r.Context(), kSharedPropsCtxKey, accumulated))
+}
+
+// SharedProps returns the shared props attached to r with WithSharedProps.
+func SharedProps(r *http.Request) Props {
+	props, _ := r.Context().Value(kSharedPropsCtxKey).(Props)
+
+	return props
+}
+
 // RenderContext contains all configuration and data for rendering an Inertia.js page response.
 // It includes props, validation errors, history management options, and performance settings.
 type RenderContext struct {
@@ -133,9 +334,42 @@ type RenderContext struct {
 	// ClearHistory instructs the client to clear the history stack.
 	ClearHistory bool
 
+	// Regions are additional root views (islands) rendered on initial HTML page loads.
+	Regions []Region
+
 	// Concurrency sets the maximum number of concurrent prop resolutions for this page.
-	// If 0, uses the renderer's default. Negative values mean sequential resolution.
+	// If 0, uses the renderer's default. A value of 1 means sequential resolution
+	// and negative values mean unlimited concurrent resolution.
 	Concurrency int
+
+	// StatusCode is the HTTP status code of the response. Defaults to 200 OK if zero.
+	StatusCode int
+
+	// PollInterval hints the client how long to wait before polling the page again.
+	// It is sent as the pollInterval page field in milliseconds, if positive.
+	PollInterval time.Duration
+
+	// RenderTimeout bounds the time of the whole render, see Config.RenderTimeout.
+	// If 0, uses the renderer's default. Negative values disable the timeout.
+	RenderTimeout time.Duration
+}
+
+// Region is an additional root view mounting its own Inertia app, e.g., an island
+// or a micro-frontend, on the same page as the main component.
+//
+// Regions are rendered client-side on initial HTML page loads only. They share
+// the URL and version of the main page, and their props are resolved as on
+// a full render: optional and deferred props are skipped, unless the optional
+// prop is resolved on first load. Inertia (JSON) responses ignore regions.
+type Region struct {
+	// ID is the HTML element ID the region's app mounts to.
+	ID string
+
+	// Component is the frontend component name of the region.
+	Component string
+
+	// Props are the properties sent to the region component.
+	Props []Prop
 }
 
 // NewRenderContext creates a RenderContext configured with the provided options.
@@ -172,20 +406,62 @@ func WithEncryptHistory() Option {
 	return func(opt *RenderContext) { opt.EncryptHistory = true }
 }
 
-// WithProps adds properties to the page component.
+// WithProps adds properties from one or more sources to the page component.
 //
-// Multiple calls append additional props to the existing set.
-func WithProps(props Proper) Option {
+// Sources are appended in order. Multiple calls append additional props to the existing set.
+func WithProps(propers ...Proper) Option {
 	return func(renderCtx *RenderContext) {
-		if props == nil {
-			return
+		n := 0
+
+		for _, props := range propers {
+			if props != nil {
+				n += props.Len()
+			}
 		}
 
 		if renderCtx.Props == nil {
-			renderCtx.Props = make([]Prop, 0, props.Len())
+			renderCtx.Props = make([]Prop, 0, n)
+		}
+
+		for _, props := range propers {
+			if props == nil {
+				continue
+			}
+
+			renderCtx.Props = append(renderCtx.Props, props.Props()...)
 		}
+	}
+}
+
+// WithOptional adds an optional prop to the page component, see NewOptional.
+func WithOptional(key string, fn Lazy) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.Props = append(renderCtx.Props, NewOptional(key, fn))
+	}
+}
 
-		renderCtx.Props = append(renderCtx.Props, props.Props()...)
+// WithDeferred adds a deferred prop to the page component, see NewDeferred.
+func WithDeferred(key string, fn Lazy, opts *DeferredOptions) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.Props = append(renderCtx.Props, NewDeferred(key, fn, opts))
+	}
+}
+
+// WithAlways adds a prop that is always included in the response to the page component,
+// see NewAlways.
+func WithAlways(key string, value any) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.Props = append(renderCtx.Props, NewAlways(key, value))
+	}
+}
+
+// WithTemplateData attaches custom data to the HTML template, available as {{ .T }}.
+// Useful for page titles, meta and OG tags rendered in the HTML shell.
+//
+// The data is ignored for Inertia (JSON) responses.
+func WithTemplateData(data any) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.T = data
 	}
 }
 
@@ -193,6 +469,7 @@ func WithProps(props Proper) Option {
 // Multiple calls append errors to the same or different error bags.
 //
 // The errorBag parameter allows scoping errors to specific forms on the same page.
+// The DefaultErrorBag keeps the error bag set by a preceding option, e.g., WithErrorBag.
 func WithValidationErrors(errorers ValidationErrorer, errorBag string) Option {
 	return func(renderCtx *RenderContext) {
 		if errorers == nil {
@@ -200,32 +477,83 @@ func WithValidationErrors(errorers ValidationErrorer, errorBag string) Option {
 		}
 
 		renderCtx.AddValidationErrorer(errorers)
+
+		if errorBag != DefaultErrorBag {
+			renderCtx.ErrorBag = errorBag
+		}
+	}
+}
+
+// WithErrorBag sets the error bag validation errors are rendered under,
+// independently of supplying the errors, see RenderContext.ErrorBag.
+func WithErrorBag(errorBag string) Option {
+	return func(renderCtx *RenderContext) {
 		renderCtx.ErrorBag = errorBag
 	}
 }
 
+// WithRegion adds an additional root view mounting component with props
+// to the element with the given id, see Region.
+//
+// The rendered region is available in the HTML template as {{ index .Regions "id" }}.
+func WithRegion(id, component string, props Proper) Option {
+	return func(renderCtx *RenderContext) {
+		region := Region{ID: id, Component: component, Props: nil}
+		if props != nil {
+			region.Props = props.Props()
+		}
+
+		renderCtx.Regions = append(renderCtx.Regions, region)
+	}
+}
+
+// WithStatus sets the HTTP status code of the response, e.g., 422 for a page
+// displaying a soft failure or 201 after creating a resource.
+func WithStatus(statusCode int) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.StatusCode = statusCode
+	}
+}
+
 // WithConcurrency sets the maximum number of props that can be resolved concurrently for this page.
 // This only affects props marked as concurrent.
 //
-// A value of 0 uses the renderer's default concurrency level.
-// Negative values allow unlimited concurrent resolution.
+// A value of 0 uses the renderer's default concurrency level and 1 resolves
+// the props sequentially. Negative values allow unlimited concurrent resolution.
 func WithConcurrency(concurrency int) Option {
 	return func(renderCtx *RenderContext) {
 		renderCtx.Concurrency = concurrency
 	}
 }
 
+// WithPollInterval hints the client how long to wait before polling the page again,
+// e.g., to back off while nothing changes. It is sent as the pollInterval page field
+// in milliseconds, which the client reads to adjust its polling cadence.
+func WithPollInterval(d time.Duration) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.PollInterval = d
+	}
+}
+
+// WithRenderTimeout bounds the time of the whole render, including prop resolution
+// and server-side rendering, overriding Config.RenderTimeout.
+//
+// A value of 0 uses the renderer's default. Negative values disable the timeout.
+func WithRenderTimeout(timeout time.Duration) Option {
+	return func(renderCtx *RenderContext) {
+		renderCtx.RenderTimeout = timeout
+	}
+}
+
 // Render sends an Inertia.js page response with the specified component and context.
 // It automatically detects whether to send JSON (for Inertia requests) or HTML (for full page loads).
 //
 // This function requires the Inertia middleware to be installed in the request chain.
 // Returns an error if the middleware is not found or if rendering fails.
 func Render(w http.ResponseWriter, r *http.Request, componentName string, rCtx RenderContext) error {
-	render, ok := r.Context().Value(kCtxKey).(*Renderer)
-	if !ok {
-		return errors.New(
-			"inertia: renderer not found in request context - did you forget to use the middleware?",
-		)
+	render, err := RendererFromContext(r.Context())
+	if err != nil {
+		return err
 	}
 
 	if err := render.Render(w, r, componentName, rCtx); err != nil {
@@ -1,11 +1,16 @@
 package inertia
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"html/template"
+	"log/slog"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
 	"go.segfaultmedaddy.com/inertia/internal/inertiatest"
@@ -130,6 +135,76 @@ func TestMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, w.Code)
 	})
 
+	t.Run("version mismatch is logged", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var buf bytes.Buffer
+
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		renderer := New(tpl, &Config{Version: "2.0.0"})
+		r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{
+			Inertia: true,
+			Version: "1.0.0",
+		})
+
+		// act
+		middleware := newMiddleware(handler, renderer, func(c *MiddlewareConfig) { c.Logger = logger })
+		middleware.ServeHTTP(w, r)
+
+		// assert
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "inertia: asset version mismatch", record["msg"])
+		assert.Equal(t, "1.0.0", record["client_version"])
+		assert.Equal(t, "2.0.0", record["server_version"])
+		assert.Equal(t, "/inertia", record["path"])
+	})
+
+	t.Run("custom version extractor", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			target string
+			want   int
+		}{
+			{"matching version", "/inertia?v=2.0.0", http.StatusOK},
+			{"mismatching version", "/inertia?v=1.0.0", http.StatusConflict},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				// arrange
+				handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					_, _ = w.Write([]byte("hello"))
+				})
+
+				renderer := New(tpl, &Config{Version: "2.0.0"})
+				r, w := inertiatest.NewRequest(http.MethodGet, tt.target, &inertiatest.RequestConfig{
+					Inertia: true,
+					Version: "stale",
+				})
+
+				// act
+				middleware := newMiddleware(handler, renderer, func(c *MiddlewareConfig) {
+					c.VersionExtractor = func(r *http.Request) string { return r.URL.Query().Get("v") }
+				})
+				middleware.ServeHTTP(w, r)
+
+				// assert
+				assert.Equal(t, tt.want, w.Code)
+			})
+		}
+	})
+
 	t.Run("empty response triggers handler", func(t *testing.T) {
 		t.Parallel()
 
@@ -179,6 +254,60 @@ func TestMiddleware(t *testing.T) {
 		assert.True(t, called)
 	})
 
+	t.Run("header-only response with custom status is kept", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			status int
+		}{
+			{"not modified", http.StatusNotModified},
+			{"forbidden", http.StatusForbidden},
+			{"ok", http.StatusOK},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				// arrange
+				handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("X-Custom", "value")
+					w.WriteHeader(tt.status)
+				})
+
+				r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{Inertia: true})
+
+				// act
+				middleware := newMiddleware(handler, New(tpl, nil))
+				middleware.ServeHTTP(w, r)
+
+				// assert
+				assert.Equal(t, tt.status, w.Code)
+				assert.Equal(t, "value", w.Header().Get("X-Custom"))
+				assert.Empty(t, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("headers without status trigger empty response handler", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Custom", "value")
+		})
+
+		r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		middleware := newMiddleware(handler, New(tpl, nil))
+		middleware.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
 	t.Run("stores renderer in context for Render", func(t *testing.T) {
 		t.Parallel()
 
@@ -202,6 +331,63 @@ func TestMiddleware(t *testing.T) {
 		assert.NoError(t, renderErr)
 	})
 
+	t.Run("skipped request passes through without inertia handling", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		hasRenderer := true
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasRenderer = r.Context().Value(kCtxKey).(*Renderer)
+
+			w.WriteHeader(http.StatusOK)
+		})
+
+		renderer := New(tpl, &Config{Version: "2.0.0"})
+		r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{
+			Inertia: true,
+			Version: "1.0.0",
+		})
+
+		// act
+		middleware := newMiddleware(handler, renderer, func(c *MiddlewareConfig) {
+			c.Skipper = func(r *http.Request) bool { return r.URL.Path == "/inertia" }
+		})
+		middleware.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(inertiaheader.HeaderVary))
+		assert.False(t, hasRenderer)
+	})
+
+	t.Run("renderer override is used by Render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tenantTpl := template.Must(template.New("tenant").Parse(`<main>{{ .InertiaBody }}</main>`))
+		tenantRenderer := New(tenantTpl, &Config{Version: "tenant-1.0.0"})
+
+		var renderErr error
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			renderErr = Render(w, WithRenderer(r, tenantRenderer), "TestComponent", RenderContext{})
+		})
+
+		r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", nil)
+
+		// act
+		middleware := newMiddleware(handler, New(tpl, &Config{Version: "1.0.0"}))
+		middleware.ServeHTTP(w, r)
+
+		// assert
+		require.NoError(t, renderErr)
+
+		body := w.Body.String()
+		assert.Contains(t, body, "<main>")
+		assert.NotContains(t, body, "<!doctype html>")
+		assert.Contains(t, body, template.HTMLEscapeString(`"version":"tenant-1.0.0"`))
+	})
+
 	t.Run("redirects PUT/PATCH/DELETE with 303", func(t *testing.T) {
 		t.Parallel()
 
@@ -240,4 +426,285 @@ func TestMiddleware(t *testing.T) {
 			})
 		}
 	})
+	t.Run("redirects with 303 after handler flushes", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		redirectHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/somewhere", http.StatusFound)
+
+			err := http.NewResponseController(w).Flush()
+			assert.ErrorIs(t, err, http.ErrNotSupported)
+		})
+		r, w := inertiatest.NewRequest(http.MethodPut, "/inertia", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		newMiddleware(redirectHandler, nil).ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/somewhere", w.Header().Get("Location"))
+	})
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	renderer := New(tpl, nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(http.ResponseWriter, *http.Request) { panic("boom") })
+	mux.HandleFunc("/partial", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	})
+	handler := NewMiddleware(renderer)(NewRecoveryMiddleware(renderer, "Error")(mux))
+
+	t.Run("inertia request renders error component", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/panic", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "Error", page.Component)
+		assert.InDelta(t, 500.0, page.Props["status"], 0)
+	})
+
+	t.Run("regular request gets plain 500", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/panic", nil)
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get(inertiaheader.HeaderXInertia))
+		assert.Contains(t, w.Body.String(), "Internal Server Error")
+	})
+
+	t.Run("written response is left as is", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, w := inertiatest.NewRequest(http.MethodGet, "/partial", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		handler.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, "partial", w.Body.String())
+	})
+
+	t.Run("panic is logged with renderer logger", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var buf bytes.Buffer
+
+		//nolint:exhaustruct
+		renderer := New(tpl, &Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+		h := NewMiddleware(renderer)(NewRecoveryMiddleware(renderer, "Error")(mux))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/panic", nil)
+
+		// act
+		h.ServeHTTP(w, r)
+
+		// assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, buf.String(), "inertia: recovered from panic")
+		assert.Contains(t, buf.String(), "panic=boom")
+	})
+
+	t.Run("abort handler panic is propagated", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		h := NewRecoveryMiddleware(renderer, "Error")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		r, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act & assert
+		assert.Panics(t, func() { h.ServeHTTP(w, r) })
+	})
+}
+
+func TestWithSharedProps(t *testing.T) {
+	t.Parallel()
+
+	renderer := New(tpl, nil)
+
+	render := func(t *testing.T, h http.Handler) map[string]any {
+		t.Helper()
+
+		r, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		NewMiddleware(renderer)(h).ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		return page.Props
+	}
+
+	t.Run("shared props are rendered", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = WithSharedProps(r, Props{NewProp("user", "john", nil)})
+			_ = Render(w, r, "Index", NewRenderContext(WithProps(NewProp("title", "Home", nil))))
+		})
+
+		// act
+		props := render(t, h)
+
+		// assert
+		assert.Equal(t, "john", props["user"])
+		assert.Equal(t, "Home", props["title"])
+	})
+
+	t.Run("shared props accumulate and later ones win", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = WithSharedProps(r, Props{NewProp("user", "john", nil), NewProp("flash", "hi", nil)})
+			r = WithSharedProps(r, Props{NewProp("user", "jane", nil)})
+			_ = Render(w, r, "Index", NewRenderContext())
+		})
+
+		// act
+		props := render(t, h)
+
+		// assert
+		assert.Equal(t, "jane", props["user"])
+		assert.Equal(t, "hi", props["flash"])
+	})
+
+	t.Run("render context props override shared props", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = WithSharedProps(r, Props{NewProp("title", "Shared", nil)})
+			_ = Render(w, r, "Index", NewRenderContext(WithProps(NewProp("title", "Page", nil))))
+		})
+
+		// act
+		props := render(t, h)
+
+		// assert
+		assert.Equal(t, "Page", props["title"])
+	})
+
+	t.Run("strict props reject page props shadowing shared props", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(tpl, &Config{StrictProps: true})
+
+		var err error
+
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = WithSharedProps(r, Props{NewProp("title", "Shared", nil)})
+			err = Render(w, r, "Index", NewRenderContext(WithProps(NewProp("title", "Page", nil))))
+		})
+		r, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		NewMiddleware(renderer)(h).ServeHTTP(w, r)
+
+		// assert
+		require.ErrorIs(t, err, ErrDuplicatePropKey)
+		assert.ErrorContains(t, err, "title")
+	})
+
+	t.Run("derived requests do not leak props", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		r, _ := inertiatest.NewRequest(http.MethodGet, "/", nil)
+		base := WithSharedProps(r, Props{NewProp("a", 1, nil)})
+
+		// act
+		left := WithSharedProps(base, Props{NewProp("b", 2, nil)})
+		right := WithSharedProps(base, Props{NewProp("c", 3, nil)})
+
+		// assert
+		assert.Len(t, SharedProps(base), 1)
+		assert.Len(t, SharedProps(left), 2)
+		assert.Equal(t, "c", SharedProps(right)[1].key)
+		assert.Equal(t, "b", SharedProps(left)[1].key)
+	})
+}
+
+func TestMiddleware_StreamJSON(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	renderer := New(tpl, &Config{StreamJSON: true})
+	h := newMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, Render(w, r, "Feed", NewRenderContext(WithProps(Props{NewProp("items", []int{1, 2, 3}, nil)}))))
+	}), renderer)
+	r, w := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	h.ServeHTTP(w, r)
+
+	// assert
+	assert.True(t, w.Flushed, "response should be flushed while encoding")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+
+	var page Page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "Feed", page.Component)
+	assert.Equal(t, []any{1.0, 2.0, 3.0}, page.Props["items"])
+}
+
+// discardFlusher is an http.ResponseWriter discarding the response.
+type discardFlusher struct{ h http.Header }
+
+func (w discardFlusher) Header() http.Header         { return w.h }
+func (w discardFlusher) Write(p []byte) (int, error) { return len(p), nil }
+func (discardFlusher) WriteHeader(int)               {}
+func (discardFlusher) Flush()                        {}
+
+func BenchmarkMiddleware_StreamJSON(b *testing.B) {
+	items := make([]map[string]any, 10_000)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "title": "An item with a reasonably long title"}
+	}
+
+	for _, stream := range []bool{false, true} {
+		b.Run(fmt.Sprintf("stream=%t", stream), func(b *testing.B) {
+			renderer := New(tpl, &Config{StreamJSON: stream})
+			h := newMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = Render(w, r, "Feed", NewRenderContext(WithProps(Props{NewProp("items", items, nil)})))
+			}), renderer)
+			r, _ := inertiatest.NewRequest(http.MethodGet, "/inertia", &inertiatest.RequestConfig{Inertia: true})
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				h.ServeHTTP(discardFlusher{h: make(http.Header)}, r)
+			}
+		})
+	}
 }
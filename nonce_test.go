@@ -0,0 +1,79 @@
+package inertia
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiaheader"
+)
+
+func TestNonceMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unique nonce per request in CSP header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var nonces []string
+
+		handler := NonceMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			nonces = append(nonces, NonceFromContext(r.Context()))
+		}))
+
+		var headers []string
+
+		// act
+		for range 2 {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			headers = append(headers, w.Header().Get(inertiaheader.HeaderCSP))
+		}
+
+		// assert
+		require.Len(t, nonces, 2)
+		assert.NotEmpty(t, nonces[0])
+		assert.NotEqual(t, nonces[0], nonces[1])
+
+		for i, nonce := range nonces {
+			assert.Equal(t, "script-src 'nonce-"+nonce+"'; style-src 'nonce-"+nonce+"'", headers[i])
+		}
+	})
+
+	t.Run("nonce is available to the template", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tpl := template.Must(template.New("test").Parse(`<script nonce="{{ .Nonce }}"></script>{{ .InertiaBody }}`))
+		renderer := New(tpl, nil)
+
+		var nonce string
+
+		handler := NonceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce = NonceFromContext(r.Context())
+
+			_ = renderer.Render(w, r, "TestComponent", NewRenderContext())
+		}))
+		w := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		// assert
+		assert.Contains(t, w.Body.String(), `<script nonce="`+nonce+`"></script>`)
+	})
+
+	t.Run("no nonce without middleware", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		nonce := NonceFromContext(t.Context())
+
+		// assert
+		assert.Empty(t, nonce)
+	})
+}
@@ -0,0 +1,43 @@
+package inertia
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-json-experiment/json"
+)
+
+// ErrPropNotFound is returned by PropAs when the page has no prop with the key.
+var ErrPropNotFound = errors.New("inertia: prop not found")
+
+// PropAs returns the value of the prop with key of page as T.
+//
+// If the value isn't a T, it is converted through its JSON representation,
+// so that props of a page decoded from JSON, e.g., map[string]any or float64,
+// can be read as structs or integers. It is mostly useful in tests:
+//
+//	user, err := inertia.PropAs[User](page, "user")
+func PropAs[T any](page *Page, key string) (T, error) {
+	var zero T
+
+	v, ok := page.Prop(key)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrPropNotFound, key)
+	}
+
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return zero, fmt.Errorf("inertia: failed to convert prop %q of type %T to %T: %w", key, v, zero, err)
+	}
+
+	var t T
+	if err := json.Unmarshal(b, &t); err != nil {
+		return zero, fmt.Errorf("inertia: failed to convert prop %q of type %T to %T: %w", key, v, zero, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,87 @@
+package inertia
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPage_Prop(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	page := &Page{Props: map[string]any{"name": "Jane"}}
+
+	// act
+	present, presentOK := page.Prop("name")
+	_, absentOK := page.Prop("missing")
+	_, nilOK := (*Page)(nil).Prop("name")
+
+	// assert
+	assert.True(t, presentOK)
+	assert.Equal(t, "Jane", present)
+	assert.False(t, absentOK)
+	assert.False(t, nilOK)
+}
+
+func TestPropAs(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	page := &Page{Props: map[string]any{
+		"name":  "Jane",
+		"count": float64(3),
+		"user":  map[string]any{"name": "Jane", "age": float64(30)},
+	}}
+
+	t.Run("present", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		name, err := PropAs[string](page, "name")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", name)
+	})
+
+	t.Run("converted", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		count, countErr := PropAs[int](page, "count")
+		u, userErr := PropAs[user](page, "user")
+
+		// assert
+		require.NoError(t, countErr)
+		assert.Equal(t, 3, count)
+		require.NoError(t, userErr)
+		assert.Equal(t, user{Name: "Jane", Age: 30}, u)
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, err := PropAs[string](page, "missing")
+
+		// assert
+		require.ErrorIs(t, err, ErrPropNotFound)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		_, err := PropAs[int](page, "name")
+
+		// assert
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPropNotFound)
+	})
+}
@@ -3,6 +3,8 @@ package inertia
 import (
 	"cmp"
 	"context"
+	"fmt"
+	"slices"
 )
 
 var (
@@ -23,15 +25,20 @@ const DefaultDeferredGroup = "default"
 //
 // Attach props to a page using WithProps option.
 type Prop struct {
-	val        any
-	valFn      Lazy // optional, deferred
-	key        string
-	group      string // deferred
-	mergeable  bool
-	deferred   bool
-	lazy       bool // optional, deferred
-	ignorable  bool // false if always prop
-	concurrent bool // deferred
+	val                any
+	valFn              Lazy // optional, deferred
+	key                string
+	group              string // deferred
+	mergeable          bool
+	mergeOnPartialOnly bool
+	deferred           bool
+	lazy               bool     // optional, deferred
+	eager              bool     // optional, resolved on full renders
+	ignorable          bool     // false if always prop
+	concurrent         bool     // deferred
+	dependsOn          []string // deferred
+	skipped            bool     // excluded from the page, see PropIf
+	ssrOnly            bool     // excluded from the client page, see PropOptions.SSROnly
 }
 
 // DeferredOptions configures the behavior of deferred props.
@@ -39,6 +46,8 @@ type DeferredOptions struct {
 	// Group assigns this prop to a named deferred group.
 	//
 	// Props in the same group are resolved together when requested by the client.
+	// A partial reload requesting a group doesn't resolve the props of other groups,
+	// so expensive props are only resolved when their group is requested.
 	// Defaults to DefaultDeferredGroup if not specified.
 	Group string
 
@@ -48,11 +57,25 @@ type DeferredOptions struct {
 	// If false, the value is replaced entirely. Defaults to false.
 	Merge bool
 
+	// MergeOnPartialOnly lists the prop as mergeable on partial reloads only,
+	// omitting it on full renders. It has no effect unless Merge is set.
+	MergeOnPartialOnly bool
+
 	// Concurrent enables parallel resolution for this prop.
 	//
 	// When true, this prop can be resolved concurrently with other concurrent props
 	// within the same request, up to the configured concurrency limit.
 	Concurrent bool
+
+	// DependsOn lists the deferred groups that must be resolved before this prop's group.
+	//
+	// When the client requests a prop of the group, the props of the dependency groups
+	// are resolved first, sequentially, and their values are available to the prop's
+	// Lazy through DeferredResult. Dependency props not requested by the client are
+	// resolved but not sent. Dependencies of all props in a group apply to the whole group.
+	//
+	// Dependency cycles fail the render with ErrDeferredCycle.
+	DependsOn []string
 }
 
 type (
@@ -74,6 +97,14 @@ type (
 // Value calls `fn()`.
 func (fn LazyFunc) Value(ctx context.Context) (any, error) { return fn(ctx) }
 
+// LazyValue returns a Lazy yielding the precomputed value v,
+// e.g., to pass an already available value to NewDeferred.
+func LazyValue(v any) Lazy { return lazyValue{v: v} }
+
+type lazyValue struct{ v any }
+
+func (l lazyValue) Value(context.Context) (any, error) { return l.v, nil }
+
 // NewDeferred creates a deferred prop that is lazy-loaded by the client after initial render.
 // Deferred props reduce initial page load time by deferring expensive computations.
 //
@@ -93,12 +124,32 @@ func NewDeferred(key string, fn Lazy, opts *DeferredOptions) Prop {
 	if opts != nil {
 		prop.group = cmp.Or(opts.Group, DefaultDeferredGroup)
 		prop.mergeable = opts.Merge
+		prop.mergeOnPartialOnly = opts.MergeOnPartialOnly
 		prop.concurrent = opts.Concurrent
+		prop.dependsOn = slices.Clone(opts.DependsOn)
 	}
 
 	return prop
 }
 
+type deferredResultsCtxKey struct{}
+
+//nolint:gochecknoglobals
+var kDeferredResultsCtxKey = deferredResultsCtxKey{}
+
+// DeferredResult returns the resolved value of the deferred prop key from a group
+// the resolving prop depends on, see DeferredOptions.DependsOn.
+//
+// The values of all dependency groups resolved for the request are available,
+// including the ones of groups the resolving prop doesn't depend on.
+// It reports false if the prop is not resolved as a dependency.
+func DeferredResult(ctx context.Context, key string) (any, bool) {
+	results, _ := ctx.Value(kDeferredResultsCtxKey).(map[string]any)
+	val, ok := results[key]
+
+	return val, ok
+}
+
 // NewAlways creates a prop that is always included in responses.
 // Unlike regular props, it ignores partial reload filters (X-Inertia-Partial-Data/Except headers).
 //
@@ -127,10 +178,46 @@ func NewOptional(key string, fn Lazy) Prop {
 	}
 }
 
+// OptionalOptions configures the behavior of optional props.
+type OptionalOptions struct {
+	// ResolveOnFirstLoad includes the prop on full (non-partial) renders, e.g., the initial
+	// page load, while keeping it skippable on partial reloads that don't request it.
+	//
+	// Deferred props are never resolved on full renders regardless of this option.
+	ResolveOnFirstLoad bool
+}
+
+// NewOptionalWithOptions is like NewOptional, but configures the prop with opts.
+//
+// If opts is nil, it is equivalent to NewOptional.
+func NewOptionalWithOptions(key string, fn Lazy, opts *OptionalOptions) Prop {
+	prop := NewOptional(key, fn)
+	if opts != nil {
+		prop.eager = opts.ResolveOnFirstLoad
+	}
+
+	return prop
+}
+
 // PropOptions configures standard prop behavior.
 type PropOptions struct {
 	// Merge determines whether this prop's value is merged or replaced during partial reloads.
 	Merge bool
+
+	// MergeOnPartialOnly lists the prop as mergeable on partial reloads only,
+	// omitting it on full renders where there is no client-side value to merge into.
+	// It has no effect unless Merge is set.
+	MergeOnPartialOnly bool
+
+	// SSROnly includes the prop only in the page sent to the SSR client,
+	// omitting it from the page embedded for client-side hydration and from
+	// Inertia (JSON) responses. The prop isn't resolved unless the page is
+	// server-side rendered.
+	//
+	// As the client never sees the prop, it must only affect markup that
+	// isn't hydrated, e.g., head tags, otherwise hydration fails. Merge has
+	// no effect on SSR-only props.
+	SSROnly bool
 }
 
 // NewProp creates a standard prop included on initial page load and partial reloads.
@@ -145,12 +232,31 @@ func NewProp(key string, val any, opts *PropOptions) Prop {
 	}
 
 	if opts != nil {
-		prop.mergeable = opts.Merge
+		prop.mergeable = opts.Merge && !opts.SSROnly
+		prop.mergeOnPartialOnly = opts.MergeOnPartialOnly
+		prop.ssrOnly = opts.SSROnly
 	}
 
 	return prop
 }
 
+// PropIf returns p if cond is true, otherwise a skipped prop
+// that is excluded from the page entirely.
+//
+// It is useful for building props conditionally without branching:
+//
+//	inertia.Props{
+//		inertia.NewProp("user", user, nil),
+//		inertia.PropIf(user.IsAdmin, inertia.NewProp("stats", stats, nil)),
+//	}
+func PropIf(cond bool, p Prop) Prop {
+	if !cond {
+		p.skipped = true
+	}
+
+	return p
+}
+
 func (p Prop) Props() []Prop { return []Prop{p} }
 func (p Prop) Len() int      { return 1 }
 
@@ -182,3 +288,154 @@ type Props []Prop
 
 func (p Props) Len() int      { return len(p) }
 func (p Props) Props() []Prop { return p }
+
+// ResolveMap resolves the props into a map keyed by prop key, as they would be
+// resolved on a full (non-partial) page render, e.g., to assert endpoint output in tests.
+//
+// Lazy (optional and deferred) props are skipped, unless the optional prop
+// is resolved on first load, and so are skipped props, see PropIf.
+// Partial reload filtering and deferred groups are not taken into account.
+// Later props override earlier ones sharing the same key.
+func (p Props) ResolveMap(ctx context.Context) (map[string]any, error) {
+	props := make(Props, 0, len(p))
+
+	for _, prop := range p {
+		if !prop.lazy || prop.eager {
+			props = append(props, prop)
+		}
+	}
+
+	return ResolveProps(ctx, props)
+}
+
+// Merge combines multiple prop sources into a single collection.
+//
+// Props are deduplicated by key: when multiple sources define the same key,
+// the prop from the later source wins, but it keeps the position of the first
+// occurrence. Nil sources and skipped props (see PropIf) are ignored.
+func Merge(propers ...Proper) Props {
+	n := 0
+
+	for _, proper := range propers {
+		if proper != nil {
+			n += proper.Len()
+		}
+	}
+
+	props := make(Props, 0, n)
+	index := make(map[string]int, n)
+
+	for _, proper := range propers {
+		if proper == nil {
+			continue
+		}
+
+		for _, prop := range proper.Props() {
+			if prop.skipped {
+				continue
+			}
+
+			if i, ok := index[prop.key]; ok {
+				props[i] = prop
+
+				continue
+			}
+
+			index[prop.key] = len(props)
+			props = append(props, prop)
+		}
+	}
+
+	return props
+}
+
+// ResolveProps resolves all props of proper into a map keyed by prop key,
+// e.g., to serialize them outside of an Inertia page.
+//
+// Unlike page rendering, lazy (optional and deferred) props are resolved as well,
+// as there is no client to request them later. Skipped props are ignored and
+// later props override earlier ones sharing the same key.
+func ResolveProps(ctx context.Context, proper Proper) (map[string]any, error) {
+	if proper == nil {
+		return map[string]any{}, nil
+	}
+
+	props := proper.Props()
+	m := make(map[string]any, len(props))
+
+	for _, prop := range props {
+		if prop.skipped {
+			continue
+		}
+
+		val, err := prop.value(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("inertia: failed to resolve prop %s: %w", prop.key, err)
+		}
+
+		m[prop.key] = val
+	}
+
+	return m, nil
+}
+
+// PropsDescription lists prop keys by category, see DescribeProps.
+type PropsDescription struct {
+	// Regular lists the keys of props sent on full renders and partial reloads
+	// requesting them.
+	Regular []string
+
+	// Always lists the keys of props sent on every render, see NewAlways.
+	Always []string
+
+	// Optional lists the keys of props sent only when requested, see NewOptional.
+	Optional []string
+
+	// Deferred lists the keys of deferred props, see NewDeferred.
+	Deferred []string
+
+	// DeferredGroups maps deferred group names to the keys of their props.
+	DeferredGroups map[string][]string
+
+	// Merge lists the keys of props merged with the client-side value,
+	// regardless of their category.
+	Merge []string
+}
+
+// DescribeProps categorizes the keys of props by how they are sent to the client,
+// e.g., to inspect the props of a component from a debug endpoint.
+//
+// Skipped props (see PropIf) are ignored. Props are not resolved.
+func DescribeProps(props []Prop) PropsDescription {
+	//nolint:exhaustruct
+	desc := PropsDescription{}
+
+	for _, prop := range props {
+		if prop.skipped {
+			continue
+		}
+
+		switch {
+		case prop.deferred:
+			desc.Deferred = append(desc.Deferred, prop.key)
+
+			if desc.DeferredGroups == nil {
+				desc.DeferredGroups = make(map[string][]string)
+			}
+
+			desc.DeferredGroups[prop.group] = append(desc.DeferredGroups[prop.group], prop.key)
+		case prop.lazy:
+			desc.Optional = append(desc.Optional, prop.key)
+		case !prop.ignorable:
+			desc.Always = append(desc.Always, prop.key)
+		default:
+			desc.Regular = append(desc.Regular, prop.key)
+		}
+
+		if prop.mergeable {
+			desc.Merge = append(desc.Merge, prop.key)
+		}
+	}
+
+	return desc
+}
@@ -3,6 +3,7 @@ package inertia
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +37,17 @@ func TestProps(t *testing.T) {
 			assert.False(t, prop.mergeable)
 		})
 
+		t.Run("DependsOn is copied", func(t *testing.T) {
+			t.Parallel()
+
+			dependsOn := []string{"user"}
+			prop := NewDeferred("key", LazyValue("val"), &DeferredOptions{DependsOn: dependsOn})
+
+			dependsOn[0] = "team"
+
+			assert.Equal(t, []string{"user"}, prop.dependsOn)
+		})
+
 		t.Run("Custom group", func(t *testing.T) {
 			t.Parallel()
 
@@ -136,6 +148,21 @@ func TestProps(t *testing.T) {
 		assert.False(t, prop.deferred)
 		assert.False(t, prop.mergeable)
 		assert.False(t, prop.concurrent)
+		assert.False(t, prop.eager)
+	})
+
+	t.Run("NewOptionalWithOptions", func(t *testing.T) {
+		t.Parallel()
+
+		fn := LazyFunc(func(context.Context) (any, error) { return "val", nil })
+
+		prop := NewOptionalWithOptions("key", fn, &OptionalOptions{ResolveOnFirstLoad: true})
+		assert.True(t, prop.lazy)
+		assert.True(t, prop.ignorable)
+		assert.True(t, prop.eager)
+
+		prop = NewOptionalWithOptions("key", fn, nil)
+		assert.False(t, prop.eager)
 	})
 
 	t.Run("NewProp", func(t *testing.T) {
@@ -200,3 +227,216 @@ func TestPropsCollections(t *testing.T) {
 		assert.Equal(t, "val2", val)
 	})
 }
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("later sources override earlier ones", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		props := Merge(
+			Props{NewProp("user", "guest", nil), NewProp("flash", "hi", nil)},
+			NewAlways("user", "admin"),
+		)
+
+		// assert
+		require.Len(t, props, 2)
+		assert.Equal(t, "user", props[0].key)
+		assert.Equal(t, "admin", props[0].val)
+		assert.False(t, props[0].ignorable)
+		assert.Equal(t, "flash", props[1].key)
+	})
+
+	t.Run("dedupes keys within a source", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		props := Merge(Props{
+			NewProp("a", 1, nil),
+			NewProp("b", 2, nil),
+			NewProp("a", 3, nil),
+		})
+
+		// assert
+		require.Len(t, props, 2)
+		assert.Equal(t, "a", props[0].key)
+		assert.Equal(t, 3, props[0].val)
+		assert.Equal(t, "b", props[1].key)
+	})
+
+	t.Run("skips nil sources", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		props := Merge(nil, Props(nil), NewProp("a", 1, nil))
+
+		// assert
+		require.Len(t, props, 1)
+		assert.Equal(t, "a", props[0].key)
+	})
+}
+
+func TestPropIf(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps prop when condition holds", func(t *testing.T) {
+		t.Parallel()
+
+		prop := PropIf(true, NewProp("a", 1, nil))
+
+		assert.False(t, prop.skipped)
+		assert.Equal(t, "a", prop.key)
+	})
+
+	t.Run("skips prop otherwise", func(t *testing.T) {
+		t.Parallel()
+
+		prop := PropIf(false, NewProp("a", 1, nil))
+
+		assert.True(t, prop.skipped)
+	})
+
+	t.Run("skipped props are ignored by Merge", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		props := Merge(
+			NewProp("a", 1, nil),
+			PropIf(false, NewProp("a", 2, nil)),
+			PropIf(false, NewProp("b", 3, nil)),
+		)
+
+		// assert
+		require.Len(t, props, 1)
+		assert.Equal(t, 1, props[0].val)
+	})
+}
+
+func TestLazyValue(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	prop := NewDeferred("stats", LazyValue(map[string]int{"users": 42}), nil)
+
+	// act
+	m, err := ResolveProps(t.Context(), prop)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stats": map[string]int{"users": 42}}, m)
+	assert.True(t, prop.deferred)
+}
+
+func TestResolveProps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolves all props", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		m, err := ResolveProps(t.Context(), Props{
+			NewProp("a", 1, nil),
+			NewOptional("b", LazyFunc(func(context.Context) (any, error) { return 2, nil })),
+			NewDeferred("c", LazyFunc(func(context.Context) (any, error) { return 3, nil }), nil),
+			PropIf(false, NewProp("d", 4, nil)),
+			NewProp("a", 5, nil),
+		})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": 5, "b": 2, "c": 3}, m)
+	})
+
+	t.Run("returns resolution error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errBoom := errors.New("boom")
+
+		// act
+		_, err := ResolveProps(t.Context(), NewOptional("b", LazyFunc(func(context.Context) (any, error) {
+			return nil, errBoom
+		})))
+
+		// assert
+		require.ErrorIs(t, err, errBoom)
+	})
+}
+
+func TestProps_ResolveMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolves props of a full render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		eager := NewOptionalWithOptions("e", LazyFunc(func(context.Context) (any, error) { return 6, nil }),
+			&OptionalOptions{ResolveOnFirstLoad: true})
+
+		// act
+		m, err := Props{
+			NewProp("a", 1, nil),
+			NewOptional("b", LazyFunc(func(context.Context) (any, error) { return 2, nil })),
+			NewDeferred("c", LazyFunc(func(context.Context) (any, error) { return 3, nil }), nil),
+			PropIf(false, NewProp("d", 4, nil)),
+			NewAlways("f", 7),
+			eager,
+			NewProp("a", 5, nil),
+		}.ResolveMap(t.Context())
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": 5, "e": 6, "f": 7}, m)
+	})
+
+	t.Run("returns resolution error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errBoom := errors.New("boom")
+		props := Props{NewOptionalWithOptions("b", LazyFunc(func(context.Context) (any, error) {
+			return nil, errBoom
+		}), &OptionalOptions{ResolveOnFirstLoad: true})}
+
+		// act
+		_, err := props.ResolveMap(t.Context())
+
+		// assert
+		require.ErrorIs(t, err, errBoom)
+		assert.Contains(t, err.Error(), "prop b")
+	})
+}
+
+func TestDescribeProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	lazy := LazyFunc(func(context.Context) (any, error) { return nil, nil })
+	props := Props{
+		NewProp("a", 1, nil),
+		NewProp("b", 2, &PropOptions{Merge: true}),
+		NewAlways("c", 3),
+		NewOptional("d", lazy),
+		NewDeferred("e", lazy, nil),
+		NewDeferred("f", lazy, &DeferredOptions{Group: "stats", Merge: true}),
+		NewDeferred("g", lazy, &DeferredOptions{Group: "stats"}),
+		PropIf(false, NewProp("h", 4, nil)),
+	}
+
+	// act
+	desc := DescribeProps(props)
+
+	// assert
+	assert.Equal(t, PropsDescription{
+		Regular:  []string{"a", "b"},
+		Always:   []string{"c"},
+		Optional: []string{"d"},
+		Deferred: []string{"e", "f", "g"},
+		DeferredGroups: map[string][]string{
+			DefaultDeferredGroup: {"e"},
+			"stats":              {"f", "g"},
+		},
+		Merge: []string{"b", "f"},
+	}, desc)
+}
@@ -1,19 +1,30 @@
 package inertia
 
 import (
-	"bytes"
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
+	"log/slog"
+	"maps"
 	"net/http"
+	neturl "net/url"
+	"path"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template/parse"
+	"time"
 
 	"github.com/alitto/pond/v2"
 	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
 	"go.inout.gg/foundations/debug"
 	"go.inout.gg/foundations/must"
 
@@ -26,11 +37,22 @@ const (
 	// DefaultRootViewID is the default root HTML element ID to which
 	// the Inertia.js app is mounted.
 	DefaultRootViewID = "app"
+
+	// DefaultDataPageAttr is the default root HTML element attribute
+	// holding the serialized page.
+	DefaultDataPageAttr = "data-page"
 )
 
-// DefaultConcurrency is the default concurrency level for props resolution
-// marked as concurrently resolvable.
-var DefaultConcurrency = runtime.GOMAXPROCS(0) //nolint:gochecknoglobals
+// discardLogger is the logger used when none is configured.
+//
+//nolint:gochecknoglobals
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// DefaultConcurrency returns the default concurrency level for props resolution
+// marked as concurrently resolvable, i.e., the current value of GOMAXPROCS.
+func DefaultConcurrency() int {
+	return runtime.GOMAXPROCS(0)
+}
 
 // Page represents an Inertia.js page that is sent to the client.
 type Page = inertiabase.Page
@@ -43,6 +65,10 @@ type Config struct {
 	SSRClient SSRClient
 
 	// RootViewAttrs are HTML attributes applied to the root element.
+	//
+	// Keys must be valid HTML attribute names, e.g., "class" or "data-theme";
+	// New panics otherwise. Values are escaped; an empty value renders
+	// a valueless attribute, e.g., "hidden".
 	RootViewAttrs map[string]string
 
 	// Version identifies the current asset version (e.g., build hash or timestamp).
@@ -53,19 +79,202 @@ type Config struct {
 	// Defaults to "app" if not specified.
 	RootViewID string
 
+	// DataPageAttr is the root element attribute holding the serialized page,
+	// for client adapters expecting a name other than "data-page".
+	//
+	// Defaults to "data-page" if not specified.
+	DataPageAttr string
+
 	// JSONMarshalOptions configures JSON serialization for page props and data.
 	JSONMarshalOptions []json.Options
 
+	// IndentJSON pretty-prints the page JSON of Inertia responses and
+	// of the data page attribute, e.g., to debug pages during development.
+	//
+	// It increases the response size and must be disabled in production.
+	IndentJSON bool
+
+	// JSONContentType is the Content-Type of Inertia JSON responses,
+	// e.g., "application/json; charset=utf-8".
+	//
+	// Defaults to "application/json".
+	JSONContentType string
+
 	// Concurrency sets the default maximum number of props that can be resolved concurrently.
 	// It only affects props marked as concurrent.
 	//
-	// Defaults to runtime.GOMAXPROCS(0).
+	// A value of 1 resolves the props sequentially and negative values
+	// allow unlimited concurrent resolution. Defaults to DefaultConcurrency() if zero.
 	Concurrency int
+
+	// Translator localizes validation error messages created with a message key,
+	// see NewValidationErrorKey. The lang is the preferred language from the
+	// request's Accept-Language header, empty if the header is missing.
+	//
+	// If nil, the message key is used as the message.
+	Translator func(key string, params map[string]any, lang string) string
+
+	// OnPropsResolved is called with resolution statistics after page props are resolved.
+	//
+	// It is useful for tuning the concurrency level. If nil, no statistics are collected.
+	OnPropsResolved func(PropStats)
+
+	// SSRComponentFilter reports whether the component should be server-side rendered.
+	// Components for which it returns false are rendered client-side.
+	//
+	// If nil, all components are server-side rendered when SSRClient is set.
+	SSRComponentFilter func(component string) bool
+
+	// SSRFallback makes HTML rendering fall back to client-side rendering
+	// when the SSRClient fails, instead of returning an error.
+	SSRFallback bool
+
+	// OnSSR is called after each HTML render with whether the page was server-side
+	// rendered and the SSR error, if any. If no SSRClient is configured or
+	// the component is excluded by SSRComponentFilter, used is false and err is nil.
+	//
+	// It is useful for monitoring SSR hit rates and detecting SSR degradation.
+	OnSSR func(used bool, err error)
+
+	// ComponentNameValidator validates component names before rendering,
+	// e.g., ValidateComponentName. Rendering fails with the returned error.
+	//
+	// It is useful during development to catch component names mistaken for file paths.
+	// If nil, component names are not validated.
+	ComponentNameValidator func(name string) error
+
+	// KnownComponents lists the component names available in the frontend,
+	// e.g., derived with ComponentsFromFS. Rendering any other component fails
+	// with ErrUnknownComponent.
+	//
+	// It is useful during development to surface missing components server-side.
+	// If empty, component names are not checked.
+	KnownComponents []string
+
+	// OmitEmptyErrors omits the validation errors prop from pages without
+	// validation errors, instead of sending an empty "errors" object.
+	//
+	// Defaults to false, as the Inertia client expects the errors prop to be present.
+	OmitEmptyErrors bool
+
+	// StrictProps makes rendering fail with ErrDuplicatePropKey when multiple
+	// props share the same key, instead of the last one silently winning.
+	//
+	// It is useful during development to catch conflicting shared and page props,
+	// as page props override shared props with the same key (see WithSharedProps)
+	// otherwise.
+	StrictProps bool
+
+	// StreamJSON flushes Inertia JSON responses to the client as the page
+	// is encoded, when rendered through NewMiddleware or to a response writer
+	// implementing http.Flusher, instead of sending the page once fully encoded.
+	//
+	// It reduces memory usage and time to first byte of pages with large props.
+	// As the status code is sent with the first chunk, the middleware can't
+	// replace it afterwards.
+	StreamJSON bool
+
+	// StrictPartialHeaders makes rendering fail with ErrUnknownPartialProp when
+	// the partial reload headers (X-Inertia-Partial-Data, X-Inertia-Partial-Except
+	// and X-Inertia-Reset) name a prop the component doesn't declare.
+	//
+	// It is useful during development to catch prop names drifting between
+	// the frontend and the backend.
+	StrictPartialHeaders bool
+
+	// RenderTimeout bounds the time of a whole render, including prop
+	// resolution and server-side rendering, after which rendering fails
+	// with ErrRenderTimeout. Props must respect the context they're resolved
+	// with to stop early.
+	//
+	// It can be overridden per render with WithRenderTimeout.
+	// If zero or negative, renders are not bounded.
+	RenderTimeout time.Duration
+
+	// Logger logs rendering events, such as prop resolution failures
+	// and SSR fallbacks, with structured attributes.
+	//
+	// If nil, nothing is logged.
+	Logger *slog.Logger
+}
+
+// Render error categories. Errors returned by the Renderer while rendering
+// a page match one of them with errors.Is, keeping their original message.
+var (
+	// ErrPropResolution indicates a prop value failed to resolve.
+	ErrPropResolution = errors.New("inertia: prop resolution failed")
+
+	// ErrSSR indicates the SSR client failed to render the page.
+	ErrSSR = errors.New("inertia: server-side rendering failed")
+
+	// ErrTemplate indicates the HTML template failed to execute.
+	ErrTemplate = errors.New("inertia: template execution failed")
+
+	// ErrJSONEncode indicates the page failed to encode to JSON.
+	ErrJSONEncode = errors.New("inertia: JSON encoding failed")
+)
+
+// categoryError attaches a render error category to err without altering its message.
+type categoryError struct {
+	category error
+	err      error
+}
+
+func withCategory(category, err error) error { return &categoryError{category: category, err: err} }
+
+func (e *categoryError) Error() string   { return e.err.Error() }
+func (e *categoryError) Unwrap() []error { return []error{e.category, e.err} }
+
+// ErrRenderTimeout is returned by the Renderer when a render exceeds
+// Config.RenderTimeout or the timeout set with WithRenderTimeout.
+var ErrRenderTimeout = errors.New("inertia: render timed out")
+
+// ErrUnknownPartialProp is returned by the Renderer when Config.StrictPartialHeaders
+// is set and a partial reload header names a prop the component doesn't declare.
+// It indicates a malformed request, e.g., to be answered with 400 Bad Request.
+var ErrUnknownPartialProp = errors.New("inertia: unknown prop in partial reload header")
+
+// ErrUnknownComponent is returned by the Renderer when Config.KnownComponents is set
+// and the rendered component is not among them.
+var ErrUnknownComponent = errors.New("inertia: unknown component")
+
+// ErrDuplicatePropKey is returned by the Renderer when Config.StrictProps is enabled
+// and multiple props share the same key.
+var ErrDuplicatePropKey = errors.New("inertia: duplicate prop key")
+
+// ErrDeferredCycle is returned by the Renderer when deferred groups depend
+// on each other in a cycle, see DeferredOptions.DependsOn.
+var ErrDeferredCycle = errors.New("inertia: deferred group dependency cycle")
+
+// PropStats describes how page props were resolved during a single render.
+type PropStats struct {
+	// Total is the number of props resolved.
+	Total int
+
+	// Concurrent is the number of props resolved concurrently.
+	Concurrent int
+
+	// Deferred is the number of deferred props resolved.
+	Deferred int
+
+	// MaxParallelism is the maximum number of props that were resolved at the same time.
+	// It is 0 if no props were resolved concurrently.
+	MaxParallelism int
+
+	// Elapsed is the time spent resolving props.
+	Elapsed time.Duration
 }
 
 func (c *Config) defaults() {
 	c.RootViewID = cmp.Or(c.RootViewID, DefaultRootViewID)
-	c.Concurrency = cmp.Or(c.Concurrency, DefaultConcurrency)
+	c.DataPageAttr = cmp.Or(c.DataPageAttr, DefaultDataPageAttr)
+
+	if c.Logger == nil {
+		c.Logger = discardLogger
+	}
+
+	c.Concurrency = cmp.Or(c.Concurrency, DefaultConcurrency())
+	c.JSONContentType = cmp.Or(c.JSONContentType, inertiaheader.ContentTypeJSON)
 
 	debug.Assert(c.RootViewID != "", "RooViewID must be non-empty string")
 }
@@ -76,20 +285,51 @@ func (c *Config) defaults() {
 // Create a Renderer using New or FromFS constructor functions.
 type Renderer struct {
 	ssrClient          SSRClient
+	onPropsResolved    func(PropStats)
+	onSSR              func(used bool, err error)
+	ssrComponentFilter func(component string) bool
+	validateComponent  func(name string) error
+	knownComponents    map[string]struct{}
+	translator         func(key string, params map[string]any, lang string) string
 	jsonMarshalOptions []json.Options
 	t                  *template.Template
 	rootViewID         string
+	dataPageAttr       string
+	jsonContentType    string
 	version            string
 	rootViewAttrs      []pair[[]byte, []byte]
 	concurrency        int
+	strictProps        bool
+	ssrFallback        bool
+	omitEmptyErrors    bool
+	streamJSON         bool
+	strictPartial      bool
+	renderTimeout      time.Duration
+	logger             *slog.Logger
+	defaultsMu         sync.RWMutex
+	defaults           map[string]Props
 }
 
 // New creates a Renderer with the provided HTML template and configuration.
 //
 // If config is nil, default values are used:
 //   - RootViewID: "app"
+//   - DataPageAttr: "data-page"
 //   - Concurrency: GOMAXPROCS(0)
+//
+// It panics if config or the template is invalid.
 func New(t *template.Template, config *Config) *Renderer {
+	r, err := newRenderer(t, config)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// newRenderer creates a Renderer like New, but returns an error if config
+// or the template is invalid.
+func newRenderer(t *template.Template, config *Config) (*Renderer, error) {
 	if config == nil {
 		//nolint:exhaustruct
 		config = &Config{}
@@ -97,25 +337,199 @@ func New(t *template.Template, config *Config) *Renderer {
 
 	config.defaults()
 
+	if !isValidAttrName(config.DataPageAttr) {
+		return nil, fmt.Errorf("inertia: invalid data page attribute name %q", config.DataPageAttr)
+	}
+
 	attrs := make([]pair[[]byte, []byte], 0, len(config.RootViewAttrs))
 	for key, value := range config.RootViewAttrs {
+		if !isValidAttrName(key) {
+			return nil, fmt.Errorf("inertia: invalid root view attribute name %q", key)
+		}
+
 		attrs = append(attrs, pair[[]byte, []byte]{[]byte(key), []byte(value)})
 	}
 
 	r := &Renderer{
 		t:                  t,
 		ssrClient:          config.SSRClient,
-		jsonMarshalOptions: config.JSONMarshalOptions,
+		jsonMarshalOptions: jsonMarshalOptions(config),
 		version:            config.Version,
 		rootViewID:         config.RootViewID,
+		dataPageAttr:       config.DataPageAttr,
+		jsonContentType:    config.JSONContentType,
 		rootViewAttrs:      attrs,
 		concurrency:        config.Concurrency,
+		onPropsResolved:    config.OnPropsResolved,
+		translator:         config.Translator,
+		strictProps:        config.StrictProps,
+		ssrFallback:        config.SSRFallback,
+		onSSR:              config.OnSSR,
+		ssrComponentFilter: config.SSRComponentFilter,
+		validateComponent:  config.ComponentNameValidator,
+		knownComponents:    nil,
+		omitEmptyErrors:    config.OmitEmptyErrors,
+		streamJSON:         config.StreamJSON,
+		strictPartial:      config.StrictPartialHeaders,
+		renderTimeout:      config.RenderTimeout,
+		logger:             config.Logger,
+		defaultsMu:         sync.RWMutex{},
+		defaults:           nil,
+	}
+
+	if len(config.KnownComponents) > 0 {
+		r.knownComponents = make(map[string]struct{}, len(config.KnownComponents))
+		for _, name := range config.KnownComponents {
+			r.knownComponents[name] = struct{}{}
+		}
 	}
 
 	debug.Assert(r.t != nil, "expected t to be defined")
 	debug.Assert(r.rootViewID != "", "expected RootViewID to be defined")
 
-	return r
+	if err := checkTemplate(t, config.SSRClient != nil); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// jsonMarshalOptions returns the page JSON marshal options of config.
+func jsonMarshalOptions(config *Config) []json.Options {
+	if !config.IndentJSON {
+		return config.JSONMarshalOptions
+	}
+
+	// Clip to not modify the backing array of the configured options.
+	return append(slices.Clip(config.JSONMarshalOptions), jsontext.WithIndent("  "))
+}
+
+// RegisterDefaults registers props merged into every render of the given component.
+//
+// Registered props are resolved only when the component renders, hence
+// lazy props are evaluated on each render. Shared props and page props take
+// precedence over registered defaults with the same key.
+// Calling RegisterDefaults again for the same component merges the new props
+// into the previously registered ones.
+//
+// It is safe to call RegisterDefaults concurrently with rendering.
+func (r *Renderer) RegisterDefaults(component string, props Proper) {
+	r.defaultsMu.Lock()
+	defer r.defaultsMu.Unlock()
+
+	if r.defaults == nil {
+		r.defaults = make(map[string]Props)
+	}
+
+	r.defaults[component] = Merge(r.defaults[component], props)
+}
+
+// componentDefaults returns the props registered for component with RegisterDefaults.
+func (r *Renderer) componentDefaults(component string) Props {
+	r.defaultsMu.RLock()
+	defer r.defaultsMu.RUnlock()
+
+	return r.defaults[component]
+}
+
+// ErrTemplatePlaceholder is returned by FromFS, and New panics with it, when
+// the template doesn't render the Inertia root view, i.e., it references
+// neither InertiaBody nor the inertiaApp function, or, if an SSR client
+// is configured, it doesn't reference InertiaHead.
+var ErrTemplatePlaceholder = errors.New("inertia: template is missing a placeholder")
+
+// checkTemplate returns ErrTemplatePlaceholder if the templates associated with t
+// don't reference the placeholders required to render a page.
+func checkTemplate(t *template.Template, ssr bool) error {
+	if t == nil {
+		return nil
+	}
+
+	var body, head bool
+
+	for _, tt := range t.Templates() {
+		if tt.Tree == nil {
+			continue
+		}
+
+		walkTemplate(tt.Tree.Root, func(name string) {
+			switch name {
+			case "InertiaBody", "inertiaApp":
+				body = true
+			case "InertiaHead":
+				head = true
+			}
+		})
+	}
+
+	if !body {
+		return fmt.Errorf("%w: {{ .InertiaBody }} or {{ inertiaApp . }} not found in %s", ErrTemplatePlaceholder, t.Name())
+	}
+
+	if ssr && !head {
+		return fmt.Errorf("%w: {{ .InertiaHead }} not found in %s, it is required for SSR",
+			ErrTemplatePlaceholder, t.Name())
+	}
+
+	return nil
+}
+
+// walkTemplate calls fn with the field and function names referenced by node and its children.
+func walkTemplate(node parse.Node, fn func(name string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			walkTemplate(child, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, cmd := range n.Cmds {
+			walkTemplate(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplate(arg, fn)
+		}
+	case *parse.FieldNode:
+		for _, ident := range n.Ident {
+			fn(ident)
+		}
+	case *parse.VariableNode:
+		for _, ident := range n.Ident {
+			fn(ident)
+		}
+	case *parse.ChainNode:
+		walkTemplate(n.Node, fn)
+
+		for _, field := range n.Field {
+			fn(field)
+		}
+	case *parse.IdentifierNode:
+		fn(n.Ident)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkTemplate(n.Pipe, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(name string)) {
+	walkTemplate(n.Pipe, fn)
+	walkTemplate(n.List, fn)
+	walkTemplate(n.ElseList, fn)
 }
 
 // FromFS creates a Renderer by loading an HTML template from a file system.
@@ -125,14 +539,14 @@ func FromFS(fsys fs.FS, path string, config *Config) (*Renderer, error) {
 	debug.Assert(fsys != nil, "expected fsys to be defined")
 	debug.Assert(path != "", "expected path to be defined")
 
-	t := template.New("inertia")
+	t := template.New("inertia").Funcs(FuncMap())
 
 	t, err := t.ParseFS(fsys, path)
 	if err != nil {
 		return nil, fmt.Errorf("inertia: failed to parse templates: %w", err)
 	}
 
-	return New(t, config), nil
+	return newRenderer(t, config)
 }
 
 // MustFromFS is like FromFS, but panics if an error occurs.
@@ -148,10 +562,24 @@ func (r *Renderer) Version() string { return r.version }
 //   - HTML for initial page loads or non-Inertia requests
 //
 // The renderCtx configures props, validation errors, and other page-specific settings.
+//
+// HEAD requests are responded to with the headers of the corresponding GET response only,
+// props are not resolved.
 func (r *Renderer) Render(w http.ResponseWriter, req *http.Request, name string, renderCtx RenderContext) error {
-	renderCtx.Concurrency = max(cmp.Or(renderCtx.Concurrency, r.concurrency), 0)
+	req, cancel := r.withRenderTimeout(req, renderCtx)
+	defer cancel()
+
+	return renderTimeoutError(req, r.render(w, req, name, renderCtx))
+}
+
+func (r *Renderer) render(w http.ResponseWriter, req *http.Request, name string, renderCtx RenderContext) error {
+	if req.Method == http.MethodHead {
+		return r.renderHead(w, req, name, renderCtx)
+	}
 
-	page, err := r.newPage(req, name, renderCtx)
+	renderCtx.Concurrency = cmp.Or(renderCtx.Concurrency, r.concurrency)
+
+	page, ssrProps, err := r.newPage(req, name, renderCtx, !isInertiaRequest(req) && r.ssrEnabled(name))
 	if err != nil {
 		return err
 	}
@@ -160,30 +588,45 @@ func (r *Renderer) Render(w http.ResponseWriter, req *http.Request, name string,
 		d("Received inertia request, sending JSON response: %s",
 			req.Header.Get(inertiaheader.HeaderReferer))
 
-		w.Header().Set(inertiaheader.HeaderXInertia, "true")
-		w.Header().Set(inertiaheader.HeaderContentType, inertiaheader.ContentTypeJSON)
-		w.WriteHeader(http.StatusOK)
+		return r.writeJSON(w, page, renderCtx.StatusCode)
+	}
 
-		if err := json.MarshalWrite(w, page, r.jsonMarshalOptions...); err != nil {
-			return fmt.Errorf("inertia: failed to encode JSON response: %w", err)
-		}
+	w.Header().Set(inertiaheader.HeaderContentType, inertiaheader.ContentTypeHTML)
+	w.WriteHeader(cmp.Or(renderCtx.StatusCode, http.StatusOK))
 
-		return nil
+	regions, err := r.makeRegions(req, renderCtx.Regions)
+	if err != nil {
+		return err
 	}
 
-	w.Header().Set(inertiaheader.HeaderContentType, inertiaheader.ContentTypeHTML)
-	w.WriteHeader(http.StatusOK)
+	data := TemplateData{
+		T:           renderCtx.T,
+		InertiaHead: "",
+		InertiaBody: "",
+		Regions:     regions,
+		Nonce:       NonceFromContext(req.Context()),
+		page:        page,
+		renderer:    r,
+		ssr:         false,
+	}
 
-	data := TemplateData{T: renderCtx.T, InertiaHead: "", InertiaBody: ""}
+	ssrData, err := r.renderSSR(req.Context(), pageWithProps(page, ssrProps))
+	if err != nil {
+		return err
+	}
 
-	if r.ssrClient != nil {
-		ssrData, err := r.ssrClient.Render(req.Context(), page)
-		if err != nil {
-			return fmt.Errorf("inertia: failed to render SSR data: %w", err)
+	if ssrData != nil {
+		body := ssrData.Body
+		if len(ssrProps) > 0 {
+			// Replace the page embedded by the SSR client to not hydrate with SSR-only props.
+			if body, err = r.replaceDataPage(body, page); err != nil {
+				return err
+			}
 		}
 
+		data.ssr = true
 		data.InertiaHead = template.HTML(ssrData.Head) //nolint:gosec
-		data.InertiaBody = template.HTML(ssrData.Body) //nolint:gosec
+		data.InertiaBody = template.HTML(body)         //nolint:gosec
 	} else {
 		body, err := r.makeRootView(page)
 		if err != nil {
@@ -194,26 +637,360 @@ func (r *Renderer) Render(w http.ResponseWriter, req *http.Request, name string,
 	}
 
 	if err := r.t.Execute(w, &data); err != nil {
-		return fmt.Errorf("inertia: failed to execute HTML template: %w", err)
+		return withCategory(ErrTemplate, fmt.Errorf("inertia: failed to execute HTML template: %w", err))
+	}
+
+	return nil
+}
+
+// componentFileExts are file extensions of frontend component sources.
+//
+//nolint:gochecknoglobals
+var componentFileExts = []string{".js", ".jsx", ".mjs", ".ts", ".tsx", ".vue", ".svelte"}
+
+// ValidateComponentName reports an error if name looks like a file path
+// rather than a logical component name, e.g., "Pages/Users/Index.jsx"
+// or "./Users/Index" instead of "Users/Index".
+//
+// It is meant to be used as Config.ComponentNameValidator.
+func ValidateComponentName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("component name must not be empty")
+	case strings.HasPrefix(name, "/") || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../"):
+		return errors.New("component name must not be a relative or absolute path")
+	case strings.Contains(name, "\\"):
+		return errors.New("component name must use forward slashes")
+	case slices.Contains(componentFileExts, path.Ext(name)):
+		return fmt.Errorf("component name must not have a file extension %q", path.Ext(name))
 	}
 
 	return nil
 }
 
-func (r *Renderer) newPage(req *http.Request, componentName string, renderCtx RenderContext) (*Page, error) {
-	rawProps := make([]Prop, 0, len(renderCtx.Props)+1)
-	rawProps = append(rawProps, renderCtx.Props...)
-	rawProps = append(rawProps, r.makeValidationErrors(renderCtx.ValidationErrorer, renderCtx.ErrorBag))
+// ComponentsFromFS returns the component names of the frontend component sources
+// found under root in fsys, e.g., "Users/Index" for "Pages/Users/Index.tsx" with
+// root "Pages". Only files with known component extensions (.jsx, .tsx, .vue, ...) are included.
+//
+// It is meant to be used to populate Config.KnownComponents.
+func ComponentsFromFS(fsys fs.FS, root string) ([]string, error) {
+	var names []string
+
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := path.Ext(p)
+		if d.IsDir() || !slices.Contains(componentFileExts, ext) {
+			return nil
+		}
+
+		name := strings.TrimSuffix(p, ext)
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+
+		names = append(names, name)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("inertia: failed to scan components: %w", err)
+	}
+
+	return names, nil
+}
+
+// RenderJSON sends an Inertia JSON page response, like Render does for Inertia requests,
+// regardless of whether the request is an Inertia request.
+//
+// It is useful for endpoints that are always requested by the Inertia client.
+func (r *Renderer) RenderJSON(w http.ResponseWriter, req *http.Request, name string, renderCtx RenderContext) error {
+	req, cancel := r.withRenderTimeout(req, renderCtx)
+	defer cancel()
+
+	renderCtx.Concurrency = cmp.Or(renderCtx.Concurrency, r.concurrency)
+
+	page, _, err := r.newPage(req, name, renderCtx, false)
+	if err != nil {
+		return renderTimeoutError(req, err)
+	}
+
+	return renderTimeoutError(req, r.writeJSON(w, page, renderCtx.StatusCode))
+}
+
+// withRenderTimeout returns req with its context bounded by the render timeout,
+// if any, see Config.RenderTimeout.
+func (r *Renderer) withRenderTimeout(req *http.Request, renderCtx RenderContext) (*http.Request, context.CancelFunc) {
+	timeout := cmp.Or(renderCtx.RenderTimeout, r.renderTimeout)
+	if timeout <= 0 {
+		return req, func() {}
+	}
+
+	ctx, cancel := context.WithTimeoutCause(
+		req.Context(),
+		timeout,
+		fmt.Errorf("%w after %s", ErrRenderTimeout, timeout),
+	)
+
+	return req.WithContext(ctx), cancel
+}
+
+// renderTimeoutError annotates err with the render timeout, if the render
+// failed because it exceeded the timeout set by withRenderTimeout.
+func renderTimeoutError(req *http.Request, err error) error {
+	if err == nil || errors.Is(err, ErrRenderTimeout) {
+		return err
+	}
+
+	if cause := context.Cause(req.Context()); errors.Is(cause, ErrRenderTimeout) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+
+	return err
+}
+
+// renderHead responds to a HEAD request with the headers and status code
+// of the corresponding GET response, without resolving props or writing a body.
+func (r *Renderer) renderHead(w http.ResponseWriter, req *http.Request, name string, renderCtx RenderContext) error {
+	if err := r.checkComponent(name); err != nil {
+		return err
+	}
+
+	contentType := inertiaheader.ContentTypeHTML
+	if isInertiaRequest(req) {
+		w.Header().Set(inertiaheader.HeaderXInertia, "true")
+
+		contentType = r.jsonContentType
+	}
+
+	w.Header().Set(inertiaheader.HeaderContentType, contentType)
+	w.WriteHeader(cmp.Or(renderCtx.StatusCode, http.StatusOK))
+
+	return nil
+}
+
+// writeJSON writes page as an Inertia JSON response with statusCode, or 200 OK if zero.
+func (r *Renderer) writeJSON(w http.ResponseWriter, page *Page, statusCode int) error {
+	w.Header().Set(inertiaheader.HeaderXInertia, "true")
+	w.Header().Set(inertiaheader.HeaderContentType, r.jsonContentType)
+	w.WriteHeader(cmp.Or(statusCode, http.StatusOK))
+
+	var out io.Writer = w
+
+	if r.streamJSON {
+		// The writer of the middleware isn't an http.Flusher, see streamFlusher.
+		switch f := w.(type) {
+		case streamFlusher:
+			d("streaming JSON response")
+
+			out = flushWriter{w: w, flush: f.flushStream}
+		case http.Flusher:
+			d("streaming JSON response")
+
+			out = flushWriter{w: w, flush: f.Flush}
+		}
+	}
+
+	if err := json.MarshalWrite(out, page, r.jsonMarshalOptions...); err != nil {
+		return withCategory(ErrJSONEncode, fmt.Errorf("inertia: failed to encode JSON response: %w", err))
+	}
+
+	return nil
+}
+
+// flushWriter flushes every chunk written by the JSON encoder to the client.
+type flushWriter struct {
+	w     io.Writer
+	flush func()
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err != nil {
+		return n, err //nolint:wrapcheck
+	}
+
+	fw.flush()
+
+	return n, nil
+}
+
+// renderSSR renders page with the SSR client, if configured and enabled for the page component.
+//
+// It returns nil data if the page must be rendered client-side.
+func (r *Renderer) renderSSR(ctx context.Context, page *Page) (*SsrTemplateData, error) {
+	if !r.ssrEnabled(page.Component) {
+		if r.onSSR != nil {
+			r.onSSR(false, nil)
+		}
+
+		return nil, nil
+	}
+
+	ssrData, err := r.ssrClient.Render(ctx, page)
+	if r.onSSR != nil {
+		r.onSSR(err == nil, err)
+	}
+
+	if err != nil {
+		if r.ssrFallback {
+			r.logger.WarnContext(ctx, "inertia: SSR failed, falling back to client-side rendering",
+				slog.String("component", page.Component), slog.Any("error", err))
+
+			return nil, nil
+		}
+
+		return nil, withCategory(ErrSSR, fmt.Errorf("inertia: failed to render SSR data: %w", err))
+	}
+
+	return ssrData, nil
+}
+
+// ssrEnabled reports whether pages of the component are server-side rendered.
+func (r *Renderer) ssrEnabled(componentName string) bool {
+	return r.ssrClient != nil && (r.ssrComponentFilter == nil || r.ssrComponentFilter(componentName))
+}
+
+// pageWithProps returns a copy of page with props added, or page itself if props is empty.
+func pageWithProps(page *Page, props map[string]any) *Page {
+	if len(props) == 0 {
+		return page
+	}
+
+	p := *page
+	p.Props = make(map[string]any, len(page.Props)+len(props))
+	maps.Copy(p.Props, page.Props)
+	maps.Copy(p.Props, props)
+
+	return &p
+}
+
+// replaceDataPage replaces the value of the first data page attribute in the SSR body
+// with page, i.e., the page embedded by the SSR client for client-side hydration.
+//
+// The attribute value is expected to be double-quoted, as rendered by the Inertia SSR server.
+func (r *Renderer) replaceDataPage(body string, page *Page) (string, error) {
+	prefix := " " + r.dataPageAttr + `="`
+
+	start := strings.Index(body, prefix)
+	if start < 0 {
+		return body, nil
+	}
+
+	start += len(prefix)
+
+	end := strings.IndexByte(body[start:], '"')
+	if end < 0 {
+		return body, nil
+	}
+
+	pageBytes, err := json.Marshal(page, r.jsonMarshalOptions...)
+	if err != nil {
+		return "", withCategory(ErrJSONEncode, fmt.Errorf("inertia: an error occurred while rendering page: %w", err))
+	}
+
+	var w strings.Builder
+
+	_ = must.Must(w.WriteString(body[:start]))
+	template.HTMLEscape(&w, pageBytes)
+	_ = must.Must(w.WriteString(body[start+end:]))
+
+	return w.String(), nil
+}
+
+// checkComponent validates componentName with the configured validator and known components.
+func (r *Renderer) checkComponent(componentName string) error {
+	if r.validateComponent != nil {
+		if err := r.validateComponent(componentName); err != nil {
+			return fmt.Errorf("inertia: invalid component name %q: %w", componentName, err)
+		}
+	}
+
+	if r.knownComponents != nil {
+		if _, ok := r.knownComponents[componentName]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownComponent, componentName)
+		}
+	}
+
+	return nil
+}
+
+// newPage creates the page of the component rendered with renderCtx.
+//
+// If ssr is true, the SSR-only props are resolved and returned separately from
+// the page, see PropOptions.SSROnly. Otherwise, they are omitted.
+func (r *Renderer) newPage(
+	req *http.Request,
+	componentName string,
+	renderCtx RenderContext,
+	ssr bool,
+) (*Page, map[string]any, error) {
+	if err := r.checkComponent(componentName); err != nil {
+		return nil, nil, err
+	}
+
+	shared := SharedProps(req)
+	if defaults := r.componentDefaults(componentName); len(defaults) > 0 {
+		shared = Merge(defaults, shared)
+	}
+
+	if r.strictPartial && isPartialComponentRequest(req, componentName) {
+		if err := checkPartialHeaders(req, renderCtx.ErrorBag, shared, renderCtx.Props); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(renderCtx.ValidationErrorer) == 0 &&
+		!slices.ContainsFunc(renderCtx.Props, isRendered) &&
+		!slices.ContainsFunc(shared, isRendered) {
+		return r.newEmptyPage(req, componentName, renderCtx), nil, nil
+	}
+
+	pageProps := withoutSkipped(renderCtx.Props)
+
+	// Check the props as given, before page props override shared ones.
+	if r.strictProps {
+		if err := checkDuplicatePropKeys(slices.Concat(withoutSkipped(SharedProps(req)), pageProps)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rawProps := withSharedProps(shared, pageProps)
+	if !ssr {
+		rawProps = slices.DeleteFunc(rawProps, isSSROnly)
+	}
+
+	if errorsProp := r.makeValidationErrors(req, renderCtx.ValidationErrorer, renderCtx.ErrorBag); isRendered(errorsProp) {
+		rawProps = append(rawProps, errorsProp)
+	}
 
 	props, err := r.makeProps(req, componentName, rawProps, renderCtx.Concurrency)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	var ssrProps map[string]any
+
+	if ssr {
+		for _, prop := range rawProps {
+			if v, ok := props[prop.key]; ok && prop.ssrOnly {
+				if ssrProps == nil {
+					ssrProps = make(map[string]any)
+				}
+
+				ssrProps[prop.key] = v
+				delete(props, prop.key)
+			}
+		}
 	}
 
 	deferredProps := r.makeDeferredProps(req, componentName, rawProps)
 	mergeProps := r.makeMergeProps(
 		rawProps,
-		extractHeaderValueList(req.Header.Get(inertiaheader.HeaderXInertiaReset)),
+		ResetProps(req),
+		isPartialComponentRequest(req, componentName),
 	)
 
 	return &Page{
@@ -225,37 +1002,189 @@ func (r *Renderer) newPage(req *http.Request, componentName string, renderCtx Re
 		Version:        r.version,
 		ClearHistory:   renderCtx.ClearHistory,
 		EncryptHistory: renderCtx.EncryptHistory,
-	}, nil
+		PollInterval:   pollInterval(renderCtx.PollInterval),
+	}, ssrProps, nil
+}
+
+// newEmptyPage creates a page without props other than empty validation errors,
+// avoiding the allocations of the full props pipeline.
+//
+// It produces the same page as newPage does for a render context without props.
+func (r *Renderer) newEmptyPage(req *http.Request, componentName string, renderCtx RenderContext) *Page {
+	props := make(map[string]any, 1)
+
+	switch {
+	case r.omitEmptyErrors:
+	case renderCtx.ErrorBag != DefaultErrorBag:
+		props[renderCtx.ErrorBag] = map[string]map[string]string{"errors": {}}
+	default:
+		props["errors"] = map[string]string{}
+	}
+
+	if r.onPropsResolved != nil {
+		//nolint:exhaustruct
+		r.onPropsResolved(PropStats{Total: len(props)})
+	}
+
+	return &Page{
+		Component:      componentName,
+		Props:          props,
+		DeferredProps:  nil,
+		MergeProps:     nil,
+		URL:            req.RequestURI,
+		Version:        r.version,
+		ClearHistory:   renderCtx.ClearHistory,
+		EncryptHistory: renderCtx.EncryptHistory,
+		PollInterval:   pollInterval(renderCtx.PollInterval),
+	}
+}
+
+// pollInterval returns the poll interval page field of d in milliseconds,
+// where zero omits the field from the page.
+func pollInterval(d time.Duration) int64 { return max(d.Milliseconds(), 0) }
+
+// isRendered reports whether prop is not skipped, see PropIf.
+func isRendered(prop Prop) bool { return !prop.skipped }
+
+// isSSROnly reports whether prop is included in server-side rendered pages only,
+// see PropOptions.SSROnly.
+func isSSROnly(prop Prop) bool { return prop.ssrOnly }
+
+// makeRootView creates a root view element with the given page data.
+//
+// The extraAttrs are written after the configured root view attributes.
+func (r *Renderer) makeRootView(page *Page, extraAttrs ...pair[[]byte, []byte]) (template.HTML, error) {
+	return r.makeView(r.rootViewID, page, r.rootViewAttrs, extraAttrs)
+}
+
+// isValidAttrName reports whether name is a safe HTML attribute name,
+// i.e., it starts with a letter, '_' or ':' followed by letters, digits,
+// '-', '_', ':' or '.'.
+func isValidAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// withSharedProps prepends the shared props not overridden by props to props.
+func withSharedProps(shared Props, props []Prop) []Prop {
+	if len(shared) == 0 {
+		return props
+	}
+
+	keys := make(map[string]struct{}, len(props))
+	for _, prop := range props {
+		keys[prop.key] = struct{}{}
+	}
+
+	ret := make([]Prop, 0, len(shared)+len(props)+1)
+	for _, prop := range Merge(shared) {
+		if _, ok := keys[prop.key]; !ok {
+			ret = append(ret, prop)
+		}
+	}
+
+	return append(ret, props...)
+}
+
+// withoutSkipped returns a copy of props without skipped props, see PropIf.
+func withoutSkipped(props []Prop) []Prop {
+	ret := make([]Prop, 0, len(props)+1)
+	for _, prop := range props {
+		if !prop.skipped {
+			ret = append(ret, prop)
+		}
+	}
+
+	return ret
+}
+
+// makeRegions creates root view elements of the regions keyed by region ID.
+func (r *Renderer) makeRegions(req *http.Request, regions []Region) (map[string]template.HTML, error) {
+	if len(regions) == 0 {
+		return nil, nil
+	}
+
+	var stats PropStats
+
+	m := make(map[string]template.HTML, len(regions))
+
+	for _, region := range regions {
+		debug.Assert(region.ID != "", "region ID must be non-empty")
+
+		props, err := r.resolveComponentRequest(req.Context(), withoutSkipped(region.Props), &stats)
+		if err != nil {
+			return nil, withCategory(ErrPropResolution,
+				fmt.Errorf("inertia: failed to resolve props of region %s: %w", region.ID, err))
+		}
+
+		//nolint:exhaustruct
+		page := &Page{
+			Component: region.Component,
+			Props:     props,
+			URL:       req.RequestURI,
+			Version:   r.version,
+		}
+
+		view, err := r.makeView(region.ID, page, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		m[region.ID] = view
+	}
+
+	return m, nil
 }
 
-// makeRootView creates a root view element with the given page data.
-func (r *Renderer) makeRootView(page *Page) (template.HTML, error) {
+// makeView creates a root view element with the given id, page data and attributes.
+func (r *Renderer) makeView(id string, page *Page, attrs ...[]pair[[]byte, []byte]) (template.HTML, error) {
 	var w strings.Builder
 
 	_ = must.Must(w.WriteString(`<div id="`))
-	_ = must.Must(w.WriteString(r.rootViewID))
+	template.HTMLEscape(&w, []byte(id))
 	_ = must.Must(w.WriteRune('"'))
 	_ = must.Must(w.WriteRune(' '))
 
-	_ = must.Must(w.WriteString(`data-page="`))
+	_ = must.Must(w.WriteString(r.dataPageAttr))
+	_ = must.Must(w.WriteString(`="`))
 
 	pageBytes, err := json.Marshal(page, r.jsonMarshalOptions...)
 	if err != nil {
-		return "", fmt.Errorf("inertia: an error occurred while rendering page: %w", err)
+		return "", withCategory(ErrJSONEncode, fmt.Errorf("inertia: an error occurred while rendering page: %w", err))
 	}
 
 	template.HTMLEscape(&w, pageBytes)
 	_ = must.Must(w.WriteRune('"'))
 	_ = must.Must(w.WriteRune(' '))
 
-	if r.rootViewAttrs != nil {
-		for _, kv := range r.rootViewAttrs {
-			// Skip the data-page attribute as it's already set.
-			if bytes.Equal(kv.key, []byte("data-page")) {
+	for _, attrs := range attrs {
+		for _, kv := range attrs {
+			// Skip the id and page attributes as they're already set.
+			if string(kv.key) == r.dataPageAttr || string(kv.key) == "id" {
 				continue
 			}
 
 			_ = must.Must(w.Write(kv.key))
+
+			// Attributes without a value, e.g., hidden, are written bare.
+			if len(kv.value) == 0 {
+				_ = must.Must(w.WriteRune(' '))
+
+				continue
+			}
+
 			_ = must.Must(w.WriteRune('='))
 			_ = must.Must(w.WriteRune('"'))
 			template.HTMLEscape(&w, kv.value)
@@ -276,23 +1205,150 @@ func (r *Renderer) makeProps(
 	props []Prop,
 	concurrency int,
 ) (map[string]any, error) {
+	var (
+		m     map[string]any
+		err   error
+		stats PropStats
+	)
+
 	ctx := req.Context()
+	start := time.Now()
+
+	// Validate the deferred groups dependencies on all renders, so that
+	// a misconfiguration surfaces on the initial page load.
+	deps, err := newDeferredDeps(props)
+	if err != nil {
+		return nil, err
+	}
 
 	// If the request is a partial, we need to filter the props.
 	if isPartialComponentRequest(req, componentName) {
-		whitelist := extractHeaderValueList(req.Header.Get(
-			inertiaheader.HeaderXInertiaPartialData))
-		blacklist := extractHeaderValueList(req.Header.Get(
-			inertiaheader.HeaderXInertiaPartialExcept))
+		whitelist := PartialOnly(req)
+		blacklist := PartialExcept(req)
+
+		r.warnUnmatchedPartialProps(ctx, componentName, props, whitelist)
+
+		if deps != nil {
+			ctx, props, err = deps.resolve(ctx, props, whitelist, blacklist)
+		}
+
+		if err == nil {
+			m, err = r.resolvePartialComponentRequest(ctx, props, whitelist, blacklist, concurrency, &stats)
+		}
+	} else {
+		m, err = r.resolveComponentRequest(ctx, props, &stats)
+	}
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "inertia: failed to resolve props",
+			slog.String("component", componentName), slog.Any("error", err))
+
+		return nil, withCategory(ErrPropResolution, err)
+	}
+
+	if r.onPropsResolved != nil {
+		stats.Elapsed = time.Since(start)
+		r.onPropsResolved(stats)
+	}
+
+	return m, nil
+}
+
+// warnUnmatchedPartialProps logs the props requested by a partial reload that
+// match no declared prop, e.g., a deferred group name requested instead of its
+// prop keys, which would otherwise produce a confusing empty update.
+func (r *Renderer) warnUnmatchedPartialProps(ctx context.Context, componentName string, props []Prop, whitelist []string) {
+	var unmatched []string
+
+	for _, key := range whitelist {
+		if !slices.ContainsFunc(props, func(p Prop) bool { return p.key == key }) {
+			unmatched = append(unmatched, key)
+		}
+	}
+
+	if len(unmatched) == 0 {
+		return
+	}
+
+	var groups []string
+
+	for _, prop := range props {
+		if prop.deferred && !slices.Contains(groups, prop.group) {
+			groups = append(groups, prop.group)
+		}
+	}
+
+	d("partial reload of %s requested undeclared props %v, deferred groups: %v", componentName, unmatched, groups)
+
+	r.logger.DebugContext(ctx, "inertia: partial reload requested props matching no declared prop",
+		slog.String("component", componentName),
+		slog.Any("props", unmatched),
+		slog.Any("deferred_groups", groups))
+}
+
+// poolSize returns the pond pool size for the concurrency level,
+// where negative values mean unlimited, i.e., pond's zero size.
+func poolSize(concurrency int) int {
+	return max(concurrency, 0)
+}
+
+// checkPartialHeaders returns ErrUnknownPartialProp naming the first prop
+// listed in the partial reload headers of req that is not declared in props,
+// nor is the validation errors prop.
+func checkPartialHeaders(req *http.Request, errorBag string, props ...[]Prop) error {
+	declared := map[string]struct{}{"errors": {}}
+	if errorBag != "" {
+		declared[errorBag] = struct{}{}
+	}
+
+	for _, props := range props {
+		for _, prop := range props {
+			declared[prop.key] = struct{}{}
+		}
+	}
+
+	for _, header := range []string{
+		inertiaheader.HeaderXInertiaPartialData,
+		inertiaheader.HeaderXInertiaPartialExcept,
+		inertiaheader.HeaderXInertiaReset,
+	} {
+		for _, key := range extractHeaderValueList(req.Header.Get(header)) {
+			if _, ok := declared[key]; !ok {
+				return fmt.Errorf("%w: %s in %s", ErrUnknownPartialProp, key, header)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicatePropKeys returns ErrDuplicatePropKey naming the first key
+// shared by multiple props.
+func checkDuplicatePropKeys(props []Prop) error {
+	seen := make(map[string]struct{}, len(props))
+
+	for _, prop := range props {
+		if _, ok := seen[prop.key]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicatePropKey, prop.key)
+		}
 
-		return r.resolvePartialComponentRequest(ctx, props, whitelist, blacklist, concurrency)
+		seen[prop.key] = struct{}{}
 	}
 
+	return nil
+}
+
+func (r *Renderer) resolveComponentRequest(
+	ctx context.Context,
+	props []Prop,
+	stats *PropStats,
+) (map[string]any, error) {
 	m := make(map[string]any, len(props))
 
 	for _, prop := range props {
-		// Skip lazy (deferred, optional) props on the first render.
-		if prop.lazy {
+		// Skip lazy (deferred, optional) props on the first render,
+		// unless the optional prop is resolved on first load.
+		if prop.lazy && !prop.eager {
 			continue
 		}
 
@@ -302,6 +1358,7 @@ func (r *Renderer) makeProps(
 		}
 
 		m[prop.key] = val
+		stats.Total++
 	}
 
 	return m, nil
@@ -312,18 +1369,20 @@ func (r *Renderer) resolvePartialComponentRequest(
 	props []Prop,
 	whitelist, blacklist []string,
 	concurrency int,
+	stats *PropStats,
 ) (map[string]any, error) {
 	m := make(map[string]any, len(props))
 	concurrentProps := make([]Prop, 0, len(props))
 
 	for _, prop := range props {
 		key := prop.key
-		if prop.ignorable {
-			// It should be fine to go through slices here, as the number of props is expected to be small.
-			if len(whitelist) > 0 && !slices.Contains(whitelist, key) ||
-				len(blacklist) > 0 && slices.Contains(blacklist, key) {
-				continue
-			}
+		if !isRequested(prop, whitelist, blacklist) {
+			continue
+		}
+
+		stats.Total++
+		if prop.deferred {
+			stats.Deferred++
 		}
 
 		if prop.concurrent {
@@ -339,20 +1398,37 @@ func (r *Renderer) resolvePartialComponentRequest(
 	}
 
 	if len(concurrentProps) > 0 {
-		pool := pond.NewResultPool[pair[string, any]](concurrency)
-		group := pool.NewGroupContext(ctx)
+		// The group context is canceled as soon as any prop fails, so that
+		// siblings still in flight can stop early. The cancellation cause
+		// holds the first prop error.
+		groupCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		var inflight, maxInflight atomic.Int64
+
+		pool := pond.NewResultPool[pair[string, any]](poolSize(concurrency))
+		group := pool.NewGroupContext(groupCtx)
 
 		for _, prop := range concurrentProps {
 			group.SubmitErr(func() (pair[string, any], error) {
 				var kv pair[string, any]
 
-				val, err := prop.value(ctx)
+				n := inflight.Add(1)
+				defer inflight.Add(-1)
+
+				for {
+					curr := maxInflight.Load()
+					if n <= curr || maxInflight.CompareAndSwap(curr, n) {
+						break
+					}
+				}
+
+				val, err := prop.value(groupCtx)
 				if err != nil {
-					return kv, fmt.Errorf(
-						"inertia: failed to resolve prop %s: %w",
-						prop.key,
-						err,
-					)
+					err = fmt.Errorf("inertia: failed to resolve prop %s: %w", prop.key, err)
+					cancel(err)
+
+					return kv, err
 				}
 
 				kv.key = prop.key
@@ -364,17 +1440,189 @@ func (r *Renderer) resolvePartialComponentRequest(
 
 		result, err := group.Wait()
 		if err != nil {
+			// A sibling may have reported the cancellation before the failing prop
+			// was recorded by the group, prefer the original error in that case.
+			if cause := context.Cause(groupCtx); cause != nil {
+				err = cause
+			}
+
 			return nil, fmt.Errorf("inertia: failed to resolve concurrent props: %w", err)
 		}
 
 		for i, prop := range concurrentProps {
 			m[prop.key] = result[i].value
 		}
+
+		stats.Concurrent = len(concurrentProps)
+		stats.MaxParallelism = int(maxInflight.Load())
 	}
 
 	return m, nil
 }
 
+// isRequested reports whether prop is included in a partial reload
+// filtering props by the whitelist and blacklist.
+func isRequested(prop Prop, whitelist, blacklist []string) bool {
+	if !prop.ignorable {
+		return true
+	}
+
+	// It should be fine to go through slices here, as the number of props is expected to be small.
+	return (len(whitelist) == 0 || slices.Contains(whitelist, prop.key)) &&
+		(len(blacklist) == 0 || !slices.Contains(blacklist, prop.key))
+}
+
+// deferredDeps describes dependencies between deferred groups, see DeferredOptions.DependsOn.
+type deferredDeps struct {
+	// dependsOn maps a group to the groups it depends on.
+	dependsOn map[string][]string
+
+	// order lists the groups so that each group comes after its dependencies.
+	order []string
+}
+
+// newDeferredDeps collects dependencies between the deferred groups of props.
+//
+// It returns nil if no group has dependencies, or an error if a group
+// depends on an unknown group or groups depend on each other in a cycle.
+func newDeferredDeps(props []Prop) (*deferredDeps, error) {
+	if !slices.ContainsFunc(props, func(p Prop) bool { return p.deferred && len(p.dependsOn) > 0 }) {
+		return nil, nil
+	}
+
+	groups := make([]string, 0, len(props))
+	dependsOn := make(map[string][]string, len(props))
+
+	for _, prop := range props {
+		if !prop.deferred {
+			continue
+		}
+
+		if _, ok := dependsOn[prop.group]; !ok {
+			groups = append(groups, prop.group)
+			dependsOn[prop.group] = nil
+		}
+
+		for _, dep := range prop.dependsOn {
+			if !slices.Contains(dependsOn[prop.group], dep) {
+				dependsOn[prop.group] = append(dependsOn[prop.group], dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	deps := &deferredDeps{dependsOn: dependsOn, order: make([]string, 0, len(groups))}
+	state := make(map[string]int, len(groups))
+	path := make([]string, 0, len(groups))
+
+	var visit func(group string) error
+
+	visit = func(group string) error {
+		switch state[group] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := slices.Concat(path[slices.Index(path, group):], []string{group})
+			return fmt.Errorf("%w: %s", ErrDeferredCycle, strings.Join(cycle, " -> "))
+		}
+
+		state[group] = visiting
+		path = append(path, group)
+
+		for _, dep := range dependsOn[group] {
+			if _, ok := dependsOn[dep]; !ok {
+				return fmt.Errorf("inertia: deferred group %s depends on unknown group %s", group, dep)
+			}
+
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[group] = visited
+		deps.order = append(deps.order, group)
+
+		return nil
+	}
+
+	for _, group := range groups {
+		if err := visit(group); err != nil {
+			return nil, err
+		}
+	}
+
+	return deps, nil
+}
+
+// resolve resolves the props of the groups the requested deferred props depend on.
+//
+// It returns ctx carrying the resolved values for DeferredResult, and a copy
+// of props with the resolved props holding their values, so that requested
+// dependency props are not resolved twice.
+func (d *deferredDeps) resolve(
+	ctx context.Context,
+	props []Prop,
+	whitelist, blacklist []string,
+) (context.Context, []Prop, error) {
+	needed := make(map[string]bool, len(d.dependsOn))
+
+	var need func(group string)
+
+	need = func(group string) {
+		for _, dep := range d.dependsOn[group] {
+			if !needed[dep] {
+				needed[dep] = true
+				need(dep)
+			}
+		}
+	}
+
+	for _, prop := range props {
+		if prop.deferred && isRequested(prop, whitelist, blacklist) {
+			need(prop.group)
+		}
+	}
+
+	if len(needed) == 0 {
+		return ctx, props, nil
+	}
+
+	results := make(map[string]any)
+	ctx = context.WithValue(ctx, kDeferredResultsCtxKey, results)
+	props = slices.Clone(props)
+
+	for _, group := range d.order {
+		if !needed[group] {
+			continue
+		}
+
+		for i, prop := range props {
+			if !prop.deferred || prop.group != group {
+				continue
+			}
+
+			val, err := prop.value(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("inertia: failed to resolve prop %s: %w", prop.key, err)
+			}
+
+			results[prop.key] = val
+
+			props[i].valFn = nil
+			props[i].val = val
+			props[i].concurrent = false
+		}
+	}
+
+	return ctx, props, nil
+}
+
 // makeDeferredProps creates a map of deferred props that should be resolved
 // on the client side.
 func (r *Renderer) makeDeferredProps(req *http.Request, componentName string, props []Prop) map[string][]string {
@@ -386,12 +1634,26 @@ func (r *Renderer) makeDeferredProps(req *http.Request, componentName string, pr
 	}
 
 	m := make(map[string][]string, len(props))
+	seen := make(map[string]string, len(props))
 
 	for _, prop := range props {
 		if !prop.deferred {
 			continue
 		}
 
+		// List each prop in one group only, so the client doesn't fetch it twice.
+		if group, ok := seen[prop.key]; ok {
+			r.logger.WarnContext(req.Context(), "inertia: duplicate deferred prop",
+				slog.String("component", componentName),
+				slog.String("prop", prop.key),
+				slog.String("group", group),
+				slog.String("duplicate_group", prop.group))
+
+			continue
+		}
+
+		seen[prop.key] = prop.group
+
 		if _, ok := m[prop.group]; !ok {
 			m[prop.group] = []string{}
 		}
@@ -404,11 +1666,29 @@ func (r *Renderer) makeDeferredProps(req *http.Request, componentName string, pr
 
 // makeMergeProps creates a list of props that should be merged instead of
 // being replaced on the client side.
-func (r *Renderer) makeMergeProps(props []Prop, blacklist []string) []string {
+func (r *Renderer) makeMergeProps(props []Prop, blacklist []string, partial bool) []string {
+	return filterMergeProps(props, blacklist, partial)
+}
+
+// FilterMergeProps returns the keys of the mergeable props, i.e., props that
+// the client merges instead of replacing, omitting the props listed in reset,
+// as sent by the client in the X-Inertia-Reset header, see ResetProps.
+//
+// The list is the one sent on partial reloads, so props with
+// PropOptions.MergeOnPartialOnly are included. Skipped props are omitted.
+func FilterMergeProps(props []Prop, reset []string) []string {
+	return filterMergeProps(props, reset, true)
+}
+
+func filterMergeProps(props []Prop, reset []string, partial bool) []string {
 	mergeProps := make([]string, 0, len(props))
 
 	for _, p := range props {
-		if len(blacklist) > 0 && slices.Contains(blacklist, p.key) || !p.mergeable {
+		if len(reset) > 0 && slices.Contains(reset, p.key) || !p.mergeable || p.skipped {
+			continue
+		}
+
+		if p.mergeOnPartialOnly && !partial {
 			continue
 		}
 
@@ -418,16 +1698,25 @@ func (r *Renderer) makeMergeProps(props []Prop, blacklist []string) []string {
 	return mergeProps
 }
 
-func (r *Renderer) makeValidationErrors(errorers []ValidationErrorer, errorBag string) Prop {
+func (r *Renderer) makeValidationErrors(req *http.Request, errorers []ValidationErrorer, errorBag string) Prop {
 	m := make(map[string]string)
 
+	var lang string
+	if r.translator != nil {
+		lang = preferredLanguage(req.Header.Get(inertiaheader.HeaderAcceptLanguage))
+	}
+
 	for _, errorer := range errorers {
 		errs := errorer.ValidationErrors()
 		for _, err := range errs {
-			m[err.Field()] = err.Error()
+			m[err.Field()] = r.validationErrorMessage(err, lang)
 		}
 	}
 
+	if len(m) == 0 && r.omitEmptyErrors {
+		return PropIf(false, NewAlways("errors", m))
+	}
+
 	if errorBag != DefaultErrorBag {
 		return NewAlways(errorBag, map[string]map[string]string{"errors": m})
 	}
@@ -435,16 +1724,85 @@ func (r *Renderer) makeValidationErrors(errorers []ValidationErrorer, errorBag s
 	return NewAlways("errors", m)
 }
 
+// validationErrorMessage returns the message of err, localized if err carries a message key.
+func (r *Renderer) validationErrorMessage(err ValidationError, lang string) string {
+	keyer, ok := err.(MessageKeyer)
+	if !ok || r.translator == nil {
+		return err.Error()
+	}
+
+	key := keyer.MessageKey()
+	if key == "" {
+		return err.Error()
+	}
+
+	return r.translator(key, keyer.MessageParams(), lang)
+}
+
 // TemplateData contains the data passed to the HTML template during rendering.
 type TemplateData struct {
 	// T is custom application data available to the template.
 	T any
 
 	// InertiaHead contains SSR-generated head elements (title, meta tags, etc.).
+	//
+	// The HTML template is executed with and without SSR, so head elements provided
+	// by the template itself, e.g., CSS links rendered with viteResource, are kept.
+	// They are emitted wherever the template places them relative to {{ .InertiaHead }},
+	// usually before it so that SSR styles can override them.
 	InertiaHead template.HTML
 
 	// InertiaBody contains the rendered page content.
 	InertiaBody template.HTML
+
+	// Regions contains the rendered root views of additional regions keyed by region ID.
+	Regions map[string]template.HTML
+
+	// Nonce is the Content-Security-Policy nonce of the request, see NonceMiddleware.
+	Nonce string
+
+	page     *Page
+	renderer *Renderer
+	ssr      bool
+}
+
+// FuncMap returns template functions for rendering Inertia pages.
+// Templates loaded with FromFS have them registered automatically.
+//
+// The inertiaApp function renders the root element of the page, letting
+// the template position it freely and add attributes as name-value pairs:
+//
+//	<main>{{ inertiaApp . "class" "container" }}</main>
+//
+// If the page is server-side rendered, the SSR body is rendered as is
+// and the attributes are ignored.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{"inertiaApp": renderApp}
+}
+
+func renderApp(data *TemplateData, attrs ...string) (template.HTML, error) {
+	if data == nil || data.renderer == nil {
+		return "", errors.New("inertia: inertiaApp must be called with the template data")
+	}
+
+	if data.ssr {
+		return data.InertiaBody, nil
+	}
+
+	if len(attrs)%2 != 0 {
+		return "", errors.New("inertia: inertiaApp attributes must be name-value pairs")
+	}
+
+	extraAttrs := make([]pair[[]byte, []byte], 0, len(attrs)/2)
+	for i := 0; i < len(attrs); i += 2 {
+		if !isValidAttrName(attrs[i]) {
+			return "", fmt.Errorf("inertia: invalid inertiaApp attribute name %q", attrs[i])
+		}
+
+		extraAttrs = append(extraAttrs, pair[[]byte, []byte]{[]byte(attrs[i]), []byte(attrs[i+1])})
+	}
+
+	return data.renderer.makeRootView(data.page, extraAttrs...)
 }
 
 // Location redirects to an external URL outside of the Inertia app.
@@ -466,11 +1824,39 @@ func Location(w http.ResponseWriter, r *http.Request, url string) {
 	inertiaredirect.Redirect(w, r, url)
 }
 
+// Download makes the client navigate to a downloadable resource at url.
+//
+// Returning a file in response to an Inertia visit confuses the client router, so for
+// Inertia requests it uses the Location protocol to force a full browser visit.
+// For regular requests, it performs a standard HTTP redirect.
+func Download(w http.ResponseWriter, r *http.Request, url string) {
+	Location(w, r, url)
+}
+
 // Redirect sends a redirect response to the Inertia app page.
+//
+// Redirects to an external URL, i.e., an absolute URL pointing to a different host,
+// are sent using Location, as the Inertia client can't follow them with XHR.
 func Redirect(w http.ResponseWriter, r *http.Request, url string) {
+	if isExternalURL(r, url) {
+		Location(w, r, url)
+		return
+	}
+
 	inertiaredirect.Redirect(w, r, url)
 }
 
+// isExternalURL reports whether target points to a host other than the one r was sent to.
+// Relative URLs and URLs that fail to parse are considered internal.
+func isExternalURL(r *http.Request, target string) bool {
+	u, err := neturl.Parse(target)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	return !strings.EqualFold(u.Host, r.Host)
+}
+
 // ErrorBagFromRequest extracts the error bag name from the X-Inertia-Error-Bag header.
 //
 // Returns the default error bag (empty string) if the header is not present.
@@ -484,6 +1870,46 @@ func ErrorBagFromRequest(r *http.Request) string {
 	return errorBag
 }
 
+// PartialProps returns the prop keys requested by a partial reload of componentName,
+// or nil if the request is not a partial reload of componentName or doesn't
+// restrict the props.
+//
+// Props requested by a partial reload are resolved even if they were not part of
+// the initially rendered page, so handlers may use it to supply props on demand:
+//
+//	if slices.Contains(inertia.PartialProps(r, "Users/Index"), "stats") {
+//		props = append(props, inertia.NewOptional("stats", statsFn))
+//	}
+func PartialProps(req *http.Request, componentName string) []string {
+	if !isPartialComponentRequest(req, componentName) {
+		return nil
+	}
+
+	return PartialOnly(req)
+}
+
+// PartialOnly returns the props listed in the X-Inertia-Partial-Data header of req,
+// or nil if the header is missing.
+//
+// Unlike PartialProps, the partial reload component is not checked.
+func PartialOnly(req *http.Request) []string {
+	return extractHeaderValueList(req.Header.Get(inertiaheader.HeaderXInertiaPartialData))
+}
+
+// PartialExcept returns the props listed in the X-Inertia-Partial-Except header of req,
+// or nil if the header is missing.
+//
+// The partial reload component is not checked.
+func PartialExcept(req *http.Request) []string {
+	return extractHeaderValueList(req.Header.Get(inertiaheader.HeaderXInertiaPartialExcept))
+}
+
+// ResetProps returns the merge props listed in the X-Inertia-Reset header of req
+// to be replaced instead of merged, or nil if the header is missing.
+func ResetProps(req *http.Request) []string {
+	return extractHeaderValueList(req.Header.Get(inertiaheader.HeaderXInertiaReset))
+}
+
 // isInertiaRequest checks if the request is made by Inertia.js.
 func isInertiaRequest(req *http.Request) bool {
 	return req.Header.Get(inertiaheader.HeaderXInertia) == "true"
@@ -509,6 +1935,43 @@ func extractHeaderValueList(h string) []string {
 	return fields
 }
 
+// preferredLanguage returns the acceptable language tag with the highest quality
+// value from an Accept-Language header value, or an empty string if there is none.
+func preferredLanguage(h string) string {
+	lang, maxQ := "", -1.0
+
+	for _, part := range extractHeaderValueList(h) {
+		tag, params, _ := strings.Cut(part, ";")
+
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+
+			q = f
+		}
+
+		// A zero quality marks the language as not acceptable.
+		if q <= 0 {
+			continue
+		}
+
+		if q > maxQ {
+			lang, maxQ = tag, q
+		}
+	}
+
+	return lang
+}
+
 // pair is a key-value pair.
 type pair[K any, V any] struct {
 	key   K
@@ -1,13 +1,22 @@
 package inertia
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"html"
 	"html/template"
+	"log/slog"
 	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,6 +47,10 @@ func testFS() fstest.MapFS {
 			Data: []byte(testTemplate),
 			Mode: 0o644,
 		},
+		"blank/app.html": &fstest.MapFile{
+			Data: []byte(`<html><body><div id="app"></div></body></html>`),
+			Mode: 0o644,
+		},
 	}
 }
 
@@ -55,6 +68,54 @@ func TestNew(t *testing.T) {
 		{name: "empty config", tpl: testTpl},
 		{name: "valid config", tpl: testTpl, config: &Config{Version: "1.0.0", RootViewID: "test-app"}},
 		{name: "invalid RootViewID", tpl: testTpl, config: &Config{RootViewID: ""}},
+		{
+			name:   "valid root view attribute",
+			tpl:    testTpl,
+			config: &Config{RootViewAttrs: map[string]string{"data-theme": "dark", "xml:lang": "en"}},
+		},
+		{
+			name:      "unsafe root view attribute",
+			tpl:       testTpl,
+			config:    &Config{RootViewAttrs: map[string]string{`onclick="x" data-x`: "y"}},
+			wantPanic: true,
+		},
+		{
+			name:      "template without InertiaBody",
+			tpl:       template.Must(template.New("test").Parse(`<body><div id="app"></div></body>`)),
+			wantPanic: true,
+		},
+		{
+			name: "template with InertiaBody in a nested template",
+			tpl: template.Must(template.New("test").Parse(
+				`{{ define "body" }}{{ if true }}{{ $.InertiaBody }}{{ end }}{{ end }}{{ template "body" . }}`)),
+		},
+		{
+			name: "template with inertiaApp",
+			tpl:  template.Must(template.New("test").Funcs(FuncMap()).Parse(`<main>{{ inertiaApp . }}</main>`)),
+		},
+		{
+			name:      "SSR template without InertiaHead",
+			tpl:       testTpl,
+			config:    &Config{SSRClient: inertiassr.NewMockSSRClient(nil)},
+			wantPanic: true,
+		},
+		{
+			name:   "SSR template with InertiaHead",
+			tpl:    template.Must(template.New("test").Parse(`{{ .InertiaHead }}{{ .InertiaBody }}`)),
+			config: &Config{SSRClient: inertiassr.NewMockSSRClient(nil)},
+		},
+		{
+			name:      "unsafe data page attribute",
+			tpl:       testTpl,
+			config:    &Config{DataPageAttr: `data-page="x"`},
+			wantPanic: true,
+		},
+		{
+			name:      "empty root view attribute",
+			tpl:       testTpl,
+			config:    &Config{RootViewAttrs: map[string]string{"": "y"}},
+			wantPanic: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -85,7 +146,6 @@ func TestFromFS(t *testing.T) {
 		config      *Config
 		wantVersion string
 		wantErr     bool
-		wantPanic   bool
 	}{
 		{
 			name:        "valid template with config",
@@ -93,7 +153,6 @@ func TestFromFS(t *testing.T) {
 			config:      &Config{Version: "1.0.0", RootViewID: "test-app"},
 			wantVersion: "1.0.0",
 			wantErr:     false,
-			wantPanic:   false,
 		},
 		{
 			name:        "valid template without config",
@@ -101,14 +160,24 @@ func TestFromFS(t *testing.T) {
 			config:      nil,
 			wantVersion: "",
 			wantErr:     false,
-			wantPanic:   false,
 		},
 		{
-			name:      "invalid template path",
-			path:      "nonexistent/*.html",
-			config:    nil,
-			wantErr:   true,
-			wantPanic: true,
+			name:    "template without InertiaBody",
+			path:    "blank/*.html",
+			config:  nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid template path",
+			path:    "nonexistent/*.html",
+			config:  nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid config",
+			path:    "templates/*.html",
+			config:  &Config{DataPageAttr: "data page"},
+			wantErr: true,
 		},
 	}
 
@@ -116,7 +185,14 @@ func TestFromFS(t *testing.T) {
 		t.Run(tt.name+" (FromFS)", func(t *testing.T) {
 			t.Parallel()
 
-			renderer, err := FromFS(testFS(), tt.path, tt.config)
+			var (
+				renderer *Renderer
+				err      error
+			)
+
+			require.NotPanics(t, func() {
+				renderer, err = FromFS(testFS(), tt.path, tt.config)
+			}, "FromFS should return errors instead of panicking")
 
 			if tt.wantErr {
 				require.Error(t, err, "FromFS should return error with invalid template path")
@@ -133,10 +209,10 @@ func TestFromFS(t *testing.T) {
 		t.Run(tt.name+" (MustFromFS)", func(t *testing.T) {
 			t.Parallel()
 
-			if tt.wantPanic {
+			if tt.wantErr {
 				assert.Panics(t, func() {
 					MustFromFS(testFS(), tt.path, tt.config)
-				}, "FromFS should panic")
+				}, "MustFromFS should panic on error")
 
 				return
 			}
@@ -321,6 +397,51 @@ func TestRenderer_Render(t *testing.T) {
 				assert.Contains(t, bodyStr, `data-test="value"`)
 			},
 		},
+		{
+			name: "with custom data page attribute",
+			renderer: New(basicTpl, &Config{
+				Version:       "1.0.0",
+				RootViewID:    "app",
+				DataPageAttr:  "data-inertia-page",
+				RootViewAttrs: map[string]string{"data-inertia-page": "should-be-skipped"},
+			}),
+			reqConfig:          &inertiatest.RequestConfig{},
+			componentName:      "TestComponent",
+			options:            []Option{},
+			expectedStatusCode: http.StatusOK,
+			expectJSON:         false,
+			expectError:        false,
+			validateResponse: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				bodyStr := string(body)
+				assert.Contains(t, bodyStr, `<div id="app" data-inertia-page="{&#34;props&#34;`)
+				assert.Contains(t, bodyStr, `&#34;component&#34;:&#34;TestComponent&#34;`)
+				assert.NotContains(t, bodyStr, `data-page=`)
+				assert.NotContains(t, bodyStr, `should-be-skipped`)
+			},
+		},
+		{
+			name: "with valueless root view attribute",
+			renderer: New(basicTpl, &Config{
+				Version:       "1.0.0",
+				RootViewID:    "app",
+				RootViewAttrs: map[string]string{"data-turbo": ""},
+			}),
+			reqConfig:          &inertiatest.RequestConfig{},
+			componentName:      "TestComponent",
+			options:            []Option{},
+			expectedStatusCode: http.StatusOK,
+			expectJSON:         false,
+			expectError:        false,
+			validateResponse: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				bodyStr := string(body)
+				assert.Contains(t, bodyStr, `" data-turbo ></div>`)
+				assert.NotContains(t, bodyStr, `data-turbo=`)
+			},
+		},
 		{
 			name: "with validation errors",
 			renderer: New(basicTpl, &Config{
@@ -394,6 +515,46 @@ func TestRenderer_Render(t *testing.T) {
 				assert.Equal(t, "Name is required", errors["name"], "name error doesn't match")
 			},
 		},
+		{
+			name: "with error bag set separately",
+			renderer: New(basicTpl, &Config{
+				Version:    "1.0.0",
+				RootViewID: "app",
+			}),
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true,
+			},
+			componentName: "TestComponent",
+			options: []Option{
+				WithErrorBag("login"),
+				WithValidationErrors(ValidationErrors{
+					NewValidationError("email", "Invalid email"),
+				}, DefaultErrorBag),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectJSON:         false,
+			expectError:        false,
+			validateResponse: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				var page map[string]any
+
+				err := json.Unmarshal(body, &page)
+				require.NoError(t, err, "Failed to parse response JSON")
+
+				props, ok := page["props"].(map[string]any)
+				require.True(t, ok, "props not found")
+				assert.NotContains(t, props, "errors")
+
+				login, ok := props["login"].(map[string]any)
+				require.True(t, ok, "login not found")
+
+				errors, ok := login["errors"].(map[string]any)
+				require.True(t, ok, "errors not found")
+
+				assert.Equal(t, "Invalid email", errors["email"], "email error doesn't match")
+			},
+		},
 		{
 			name: "with partial component request",
 			renderer: New(basicTpl, &Config{
@@ -706,6 +867,134 @@ func TestRenderer_Render(t *testing.T) {
 	}
 }
 
+func TestRenderer_TemplateData(t *testing.T) {
+	t.Parallel()
+
+	tpl := template.Must(template.New("test").Parse(`<title>{{ .T.Title }}</title>{{ .InertiaBody }}`))
+	renderer := New(tpl, nil)
+	rCtx := NewRenderContext(WithTemplateData(struct{ Title string }{Title: "Tom & Jerry"}))
+
+	t.Run("html response exposes template data", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", rCtx)
+
+		// assert
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(), "<title>Tom &amp; Jerry</title>")
+	})
+
+	t.Run("json response ignores template data", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", rCtx)
+
+		// assert
+		require.NoError(t, err)
+		assert.NotContains(t, w.Body.String(), "Tom")
+	})
+}
+
+func TestRenderer_TranslatedValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	errs := ValidationErrors{
+		NewValidationErrorKey("password", "validation.min", map[string]any{"min": 8}),
+		NewValidationError("email", "Email is invalid"),
+	}
+
+	t.Run("translates message keys", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(testTpl, &Config{
+			Translator: func(key string, params map[string]any, lang string) string {
+				return fmt.Sprintf("%s:%s:%v", lang, key, params["min"])
+			},
+		})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		req.Header.Set("Accept-Language", "en;q=0.8, de-DE, fr;q=0.9")
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithValidationErrors(errs, DefaultErrorBag)))
+
+		// assert
+		require.NoError(t, err)
+
+		var page map[string]any
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		props, ok := page["props"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{
+			"password": "de-DE:validation.min:8",
+			"email":    "Email is invalid",
+		}, props["errors"])
+	})
+
+	t.Run("falls back to message keys", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(testTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		req.Header.Set("Accept-Language", "de-DE")
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithValidationErrors(errs, DefaultErrorBag)))
+
+		// assert
+		require.NoError(t, err)
+
+		var page map[string]any
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		props, ok := page["props"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{
+			"password": "validation.min",
+			"email":    "Email is invalid",
+		}, props["errors"])
+	})
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "empty header", header: "", expected: ""},
+		{name: "single language", header: "en-US", expected: "en-US"},
+		{name: "first language wins on equal quality", header: "fr, en", expected: "fr"},
+		{name: "highest quality wins", header: "en;q=0.5, de;q=0.9, fr;q=0.7", expected: "de"},
+		{name: "wildcard is ignored", header: "*, en;q=0.1", expected: "en"},
+		{name: "invalid quality is ignored", header: "en;q=abc, de;q=0.1", expected: "de"},
+		{name: "zero quality is not acceptable", header: "fr;q=0", expected: ""},
+		{name: "zero quality is skipped", header: "fr;q=0, en;q=0.1", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, preferredLanguage(tt.header))
+		})
+	}
+}
+
 func TestLocation(t *testing.T) {
 	t.Parallel()
 
@@ -761,6 +1050,42 @@ func TestLocation(t *testing.T) {
 	}
 }
 
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inertia request uses location conflict", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/current", &inertiatest.RequestConfig{
+			Inertia: true,
+		})
+
+		// act
+		Download(w, req, "/files/report.pdf")
+
+		// assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "/files/report.pdf", w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("non-inertia request redirects", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/current", nil)
+
+		// act
+		Download(w, req, "/files/report.pdf")
+
+		// assert
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/files/report.pdf", w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+	})
+}
+
 func TestRenderer_Version(t *testing.T) {
 	t.Parallel()
 
@@ -828,6 +1153,75 @@ func TestExtractHeaderValueList(t *testing.T) {
 	}
 }
 
+func TestFilterMergeProps(t *testing.T) {
+	t.Parallel()
+
+	props := Props{
+		NewProp("regular", "a", nil),
+		NewProp("posts", []string{"a"}, &PropOptions{Merge: true}),
+		NewProp("tags", []string{"b"}, &PropOptions{Merge: true, MergeOnPartialOnly: true}),
+		NewDeferred("comments", LazyValue([]string{"c"}), &DeferredOptions{Merge: true}),
+		PropIf(false, NewProp("skipped", []string{"d"}, &PropOptions{Merge: true})),
+	}
+
+	tests := []struct {
+		name  string
+		reset []string
+		want  []string
+	}{
+		{"no reset", nil, []string{"posts", "tags", "comments"}},
+		{"reset removes mergeable", []string{"posts", "comments"}, []string{"tags"}},
+		{"reset of unknown prop", []string{"unknown"}, []string{"posts", "tags", "comments"}},
+		{"reset of regular prop", []string{"regular"}, []string{"posts", "tags", "comments"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got := FilterMergeProps(props, tt.reset)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPartialHeaderAccessors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		fn     func(*http.Request) []string
+	}{
+		{name: "PartialOnly", header: inertiaheader.HeaderXInertiaPartialData, fn: PartialOnly},
+		{name: "PartialExcept", header: inertiaheader.HeaderXInertiaPartialExcept, fn: PartialExcept},
+		{name: "ResetProps", header: inertiaheader.HeaderXInertiaReset, fn: ResetProps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			req, _ := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+			// act & assert: missing header
+			assert.Nil(t, tt.fn(req))
+
+			// act & assert: empty header
+			req.Header.Set(tt.header, "")
+			assert.Nil(t, tt.fn(req))
+
+			// act & assert: listed props
+			req.Header.Set(tt.header, " users ,stats,,user.name")
+			assert.Equal(t, []string{"users", "stats", "", "user.name"}, tt.fn(req))
+		})
+	}
+}
+
 func TestRender_WithoutMiddleware(t *testing.T) {
 	t.Parallel()
 
@@ -838,10 +1232,58 @@ func TestRender_WithoutMiddleware(t *testing.T) {
 	err := Render(w, req, "TestComponent", RenderContext{})
 
 	// assert
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrRendererNotFound)
 	assert.Contains(t, err.Error(), "renderer not found in request context")
 }
 
+func TestRendererFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renderer attached", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(tpl, nil)
+		req, _ := inertiatest.NewRequest(http.MethodGet, "/", nil)
+		req = WithRenderer(req, renderer)
+
+		// act
+		got, err := RendererFromContext(req.Context())
+
+		// assert
+		require.NoError(t, err)
+		assert.Same(t, renderer, got)
+	})
+
+	t.Run("wrong value type", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		ctx := context.WithValue(context.Background(), kCtxKey, "not a renderer")
+
+		// act
+		_, err := RendererFromContext(ctx)
+
+		// assert
+		require.ErrorIs(t, err, ErrInvalidRenderer)
+		assert.NotErrorIs(t, err, ErrRendererNotFound)
+		assert.Contains(t, err.Error(), "string")
+	})
+
+	t.Run("nil renderer", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		ctx := context.WithValue(context.Background(), kCtxKey, (*Renderer)(nil))
+
+		// act
+		_, err := RendererFromContext(ctx)
+
+		// assert
+		require.ErrorIs(t, err, ErrInvalidRenderer)
+	})
+}
+
 func TestErrorBagFromRequest(t *testing.T) {
 	t.Parallel()
 
@@ -904,6 +1346,64 @@ func TestRedirect(t *testing.T) {
 		assert.Equal(t, http.StatusSeeOther, w.Code)
 		assert.Equal(t, "/target", w.Header().Get("Location"))
 	})
+
+	t.Run("absolute same-host URL redirects", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodPost, "http://example.com/current", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		Redirect(w, req, "http://example.com/target")
+
+		// assert
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "http://example.com/target", w.Header().Get("Location"))
+	})
+
+	t.Run("cross-host URL uses Location", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodPost, "http://example.com/current", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		Redirect(w, req, "https://auth.example.org/login")
+
+		// assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "https://auth.example.org/login", w.Header().Get(inertiaheader.HeaderXInertiaLocation))
+	})
+}
+
+func TestIsExternalURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"same-host path", "/target", false},
+		{"relative path", "target?a=1", false},
+		{"absolute same-host URL", "https://EXAMPLE.com/target", false},
+		{"cross-host URL", "https://example.org/target", true},
+		{"scheme-relative cross-host URL", "//example.org/target", true},
+		{"different port", "http://example.com:8080/target", true},
+		{"invalid URL", "http://[::1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			req, _ := inertiatest.NewRequest(http.MethodGet, "http://example.com/current", nil)
+
+			// act & assert
+			assert.Equal(t, tt.expected, isExternalURL(req, tt.url))
+		})
+	}
 }
 
 func TestRenderer_ConcurrentProps(t *testing.T) {
@@ -952,3 +1452,2067 @@ func TestRenderer_ConcurrentProps(t *testing.T) {
 	assert.Equal(t, "val-b", props["b"])
 	assert.Equal(t, "val-c", props["c"])
 }
+
+func TestRenderer_ConcurrentPropsCancellation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, &Config{
+		Version:     "1.0.0",
+		RootViewID:  "app",
+		Concurrency: 2,
+	})
+
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+		Inertia:          true,
+		PartialComponent: "TestComponent",
+		Whitelist:        []string{"fast", "slow"},
+	})
+
+	errFast := errors.New("fast prop failed")
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+
+	rCtx := NewRenderContext(
+		WithProps(Props{
+			NewDeferred("slow", LazyFunc(func(ctx context.Context) (any, error) {
+				close(started)
+
+				select {
+				case <-ctx.Done():
+					close(canceled)
+					return nil, ctx.Err()
+				case <-time.After(5 * time.Second):
+					return "val-slow", nil
+				}
+			}), &DeferredOptions{Concurrent: true}),
+			NewDeferred("fast", LazyFunc(func(context.Context) (any, error) {
+				<-started
+
+				return nil, errFast
+			}), &DeferredOptions{Concurrent: true}),
+		}),
+	)
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", rCtx)
+
+	// assert
+	require.Error(t, err)
+	require.ErrorIs(t, err, errFast)
+	assert.NotErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-canceled:
+	default:
+		assert.Fail(t, "slow prop should observe cancellation")
+	}
+}
+
+func TestRenderer_OnPropsResolved(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	props := Props{
+		NewDeferred("a", LazyFunc(func(context.Context) (any, error) {
+			return "val-a", nil
+		}), &DeferredOptions{Concurrent: true}),
+		NewDeferred("b", LazyFunc(func(context.Context) (any, error) {
+			return "val-b", nil
+		}), &DeferredOptions{Concurrent: true}),
+		NewOptional("c", LazyFunc(func(context.Context) (any, error) {
+			return "val-c", nil
+		})),
+		NewProp("d", "val-d", nil),
+	}
+
+	t.Run("full render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var stats []PropStats
+
+		renderer := New(basicTpl, &Config{
+			OnPropsResolved: func(s PropStats) { stats = append(stats, s) },
+		})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, 2, stats[0].Total) // d and errors
+		assert.Equal(t, 0, stats[0].Concurrent)
+		assert.Equal(t, 0, stats[0].Deferred)
+		assert.Equal(t, 0, stats[0].MaxParallelism)
+	})
+
+	t.Run("partial render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var stats []PropStats
+
+		renderer := New(basicTpl, &Config{
+			Concurrency:     2,
+			OnPropsResolved: func(s PropStats) { stats = append(stats, s) },
+		})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "TestComponent",
+			Whitelist:        []string{"a", "b", "c"},
+		})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, 4, stats[0].Total) // a, b, c and errors
+		assert.Equal(t, 2, stats[0].Concurrent)
+		assert.Equal(t, 2, stats[0].Deferred)
+		assert.GreaterOrEqual(t, stats[0].MaxParallelism, 1)
+		assert.LessOrEqual(t, stats[0].MaxParallelism, 2)
+	})
+}
+
+func TestRenderer_ConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	tests := []struct {
+		name               string
+		configConcurrency  int
+		contextConcurrency int
+		want               int
+	}{
+		{name: "zero uses renderer default", configConcurrency: 3, contextConcurrency: 0, want: 3},
+		{name: "one is sequential", configConcurrency: 3, contextConcurrency: 1, want: 1},
+		{name: "bounded", configConcurrency: 1, contextConcurrency: 2, want: 2},
+		{name: "negative is unbounded", configConcurrency: 1, contextConcurrency: -1, want: 4},
+		{name: "negative renderer default is unbounded", configConcurrency: -1, contextConcurrency: 0, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			const n = 4
+
+			// Each prop waits for all props to start or a timeout, so that
+			// the parallelism reaches the limit.
+			var started atomic.Int64
+
+			allStarted := make(chan struct{})
+			lazy := LazyFunc(func(context.Context) (any, error) {
+				if started.Add(1) == n {
+					close(allStarted)
+				}
+
+				select {
+				case <-allStarted:
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				return nil, nil
+			})
+
+			props := make(Props, 0, n)
+			keys := make([]string, 0, n)
+
+			for i := range n {
+				key := fmt.Sprintf("p%d", i)
+				props = append(props, NewDeferred(key, lazy, &DeferredOptions{Concurrent: true}))
+				keys = append(keys, key)
+			}
+
+			var stats PropStats
+
+			renderer := New(basicTpl, &Config{
+				Concurrency:     tt.configConcurrency,
+				OnPropsResolved: func(s PropStats) { stats = s },
+			})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+				Inertia:          true,
+				PartialComponent: "TestComponent",
+				Whitelist:        keys,
+			})
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(
+				WithProps(props),
+				WithConcurrency(tt.contextConcurrency),
+			))
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, n, stats.Concurrent)
+			assert.Equal(t, tt.want, stats.MaxParallelism)
+		})
+	}
+}
+
+func TestRenderer_StrictProps(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	props := Props{
+		NewProp("user", "guest", nil),
+		NewProp("user", "admin", nil),
+	}
+
+	t.Run("duplicate key is detected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, &Config{StrictProps: true})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.ErrorIs(t, err, ErrDuplicatePropKey)
+		assert.ErrorContains(t, err, "user")
+	})
+
+	t.Run("shared prop shadowed by page prop is detected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, &Config{StrictProps: true})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		req = WithSharedProps(req, Props{NewProp("user", "guest", nil)})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(NewProp("user", "admin", nil))))
+
+		// assert
+		require.ErrorIs(t, err, ErrDuplicatePropKey)
+		assert.ErrorContains(t, err, "user")
+	})
+
+	t.Run("struct parse collision is detected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, &Config{StrictProps: true})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		structProps, err := ParseStruct(&struct {
+			Name     string `inertia:"user"`
+			Username string `inertia:"user"`
+		}{Name: "guest", Username: "admin"})
+		require.NoError(t, err)
+
+		// act
+		err = renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(structProps)))
+
+		// assert
+		require.ErrorIs(t, err, ErrDuplicatePropKey)
+		assert.ErrorContains(t, err, "user")
+	})
+
+	t.Run("last prop wins when not strict", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "admin", page.Props["user"])
+	})
+}
+
+func TestRenderer_SkippedProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, &Config{StrictProps: true})
+	props := Props{
+		NewProp("a", "val-a", nil),
+		PropIf(false, NewProp("a", "skipped", nil)),
+		PropIf(false, NewProp("b", "val-b", &PropOptions{Merge: true})),
+		PropIf(false, NewDeferred("c", LazyFunc(func(context.Context) (any, error) {
+			return "val-c", nil
+		}), &DeferredOptions{Merge: true})),
+		PropIf(true, NewProp("d", "val-d", nil)),
+	}
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+	// assert
+	require.NoError(t, err)
+
+	var page Page
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "val-a", page.Props["a"])
+	assert.Equal(t, "val-d", page.Props["d"])
+	assert.NotContains(t, page.Props, "b")
+	assert.Empty(t, page.DeferredProps)
+	assert.Empty(t, page.MergeProps)
+}
+
+func TestRenderer_OnSSR(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaHead}}{{.InertiaBody}}`))
+	errSSR := errors.New("SSR error")
+
+	type ssrEvent struct {
+		err  error
+		used bool
+	}
+
+	tests := []struct {
+		ssrData      *SsrTemplateData
+		ssrErr       error
+		expectEvent  ssrEvent
+		name         string
+		expectBody   string
+		withSSR      bool
+		ssrFallback  bool
+		expectRender bool
+	}{
+		{
+			name:         "ssr used",
+			withSSR:      true,
+			ssrData:      &SsrTemplateData{Head: "<title>SSR</title>", Body: "<div>SSR</div>"},
+			expectEvent:  ssrEvent{used: true, err: nil},
+			expectRender: true,
+			expectBody:   "<div>SSR</div>",
+		},
+		{
+			name:         "ssr errored with fallback",
+			withSSR:      true,
+			ssrErr:       errSSR,
+			ssrFallback:  true,
+			expectEvent:  ssrEvent{used: false, err: errSSR},
+			expectRender: true,
+			expectBody:   `<div id="app" data-page=`,
+		},
+		{
+			name:         "ssr errored without fallback",
+			withSSR:      true,
+			ssrErr:       errSSR,
+			expectEvent:  ssrEvent{used: false, err: errSSR},
+			expectRender: false,
+		},
+		{
+			name:         "no ssr configured",
+			expectEvent:  ssrEvent{used: false, err: nil},
+			expectRender: true,
+			expectBody:   `<div id="app" data-page=`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var events []ssrEvent
+
+			config := &Config{
+				SSRFallback: tt.ssrFallback,
+				OnSSR:       func(used bool, err error) { events = append(events, ssrEvent{err, used}) },
+			}
+
+			if tt.withSSR {
+				ctrl := gomock.NewController(t)
+				client := inertiassr.NewMockSSRClient(ctrl)
+				client.EXPECT().Render(gomock.Any(), gomock.Any()).Return(tt.ssrData, tt.ssrErr)
+				config.SSRClient = client
+			}
+
+			renderer := New(basicTpl, config)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+			// assert
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.expectEvent.used, events[0].used)
+			assert.ErrorIs(t, events[0].err, tt.expectEvent.err)
+
+			if !tt.expectRender {
+				require.ErrorIs(t, err, errSSR)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, w.Body.String(), tt.expectBody)
+		})
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inertiaApp renders the root view", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(
+			`<main>{{ inertiaApp . "class" "container" "data-x" "a&b" "hidden" "" }}</main>`))
+		renderer := New(tpl, &Config{RootViewAttrs: map[string]string{"data-y": "y"}})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+
+		body := w.Body.String()
+		assert.True(t, strings.HasPrefix(body, `<main><div id="app" data-page="`))
+		assert.Contains(t, body, `&#34;component&#34;:&#34;TestComponent&#34;`)
+		assert.True(t, strings.HasSuffix(body, `data-y="y" class="container" data-x="a&amp;b" hidden ></div></main>`))
+	})
+
+	t.Run("inertiaApp renders ssr body", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		ctrl := gomock.NewController(t)
+		client := inertiassr.NewMockSSRClient(ctrl)
+		client.EXPECT().Render(gomock.Any(), gomock.Any()).Return(&SsrTemplateData{
+			Head: "", Body: `<div id="app">SSR</div>`,
+		}, nil)
+
+		tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(`{{ .InertiaHead }}{{ inertiaApp . "class" "container" }}`))
+		renderer := New(tpl, &Config{SSRClient: client})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, `<div id="app">SSR</div>`, w.Body.String())
+	})
+
+	t.Run("inertiaApp rejects odd attributes", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(`{{ inertiaApp . "class" }}`))
+		renderer := New(tpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.Error(t, err)
+	})
+
+	t.Run("inertiaApp rejects invalid attribute names", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tpl := template.Must(template.New("test").Funcs(FuncMap()).Parse(`{{ inertiaApp . "onload=alert(1) x" "" }}`))
+		renderer := New(tpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid inertiaApp attribute name")
+	})
+
+	t.Run("FromFS registers template functions", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		fsys := fstest.MapFS{"index.html": &fstest.MapFile{Data: []byte(`{{ define "inertia" }}{{ inertiaApp . }}{{ end }}`)}}
+
+		// act
+		renderer, err := FromFS(fsys, "index.html", nil)
+
+		// assert
+		require.NoError(t, err)
+
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		require.NoError(t, renderer.Render(w, req, "TestComponent", NewRenderContext()))
+		assert.Contains(t, w.Body.String(), `<div id="app" data-page="`)
+	})
+}
+
+func TestRenderer_Regions(t *testing.T) {
+	t.Parallel()
+
+	tpl := template.Must(template.New("test").Parse(
+		`{{ .InertiaBody }}<aside>{{ index .Regions "cart" }}</aside><footer>{{ index .Regions "chat" }}</footer>`))
+	renderer := New(tpl, &Config{Version: "1.0.0"})
+	opts := []Option{
+		WithProps(NewProp("title", "Home", nil)),
+		WithRegion("cart", "Cart", Props{
+			NewProp("items", 3, nil),
+			NewOptional("total", LazyFunc(func(context.Context) (any, error) { return 42, nil })),
+		}),
+		WithRegion("chat", "Chat", NewProp("unread", 1, nil)),
+	}
+
+	t.Run("html response renders two islands", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/home", nil)
+
+		// act
+		err := renderer.Render(w, req, "Home", NewRenderContext(opts...))
+
+		// assert
+		require.NoError(t, err)
+
+		body := w.Body.String()
+		assert.Contains(t, body, `<div id="app" data-page="`)
+		assert.Contains(t, body, `<aside><div id="cart" data-page="`)
+		assert.Contains(t, body, `<footer><div id="chat" data-page="`)
+
+		pages := extractPages(t, body)
+		require.Len(t, pages, 3)
+
+		assert.Equal(t, "Home", pages[0].Component)
+		assert.Equal(t, "Cart", pages[1].Component)
+		assert.Equal(t, map[string]any{"items": float64(3)}, pages[1].Props)
+		assert.Equal(t, "/home", pages[1].URL)
+		assert.Equal(t, "1.0.0", pages[1].Version)
+		assert.Equal(t, "Chat", pages[2].Component)
+		assert.Equal(t, map[string]any{"unread": float64(1)}, pages[2].Props)
+	})
+
+	t.Run("json response ignores islands", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/home", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "Home", NewRenderContext(opts...))
+
+		// assert
+		require.NoError(t, err)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "Home", page.Component)
+		assert.NotContains(t, page.Props, "items")
+		assert.NotContains(t, page.Props, "unread")
+	})
+}
+
+// extractPages decodes the data-page attributes of all root views in body.
+func extractPages(t *testing.T, body string) []Page {
+	t.Helper()
+
+	var pages []Page
+
+	for _, part := range strings.Split(body, `data-page="`)[1:] {
+		raw, _, _ := strings.Cut(part, `"`)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal([]byte(html.UnescapeString(raw)), &page))
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+func TestRenderer_JSONContentType(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	t.Run("overrides inertia response content type", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, &Config{JSONContentType: "application/json; charset=utf-8"})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get(inertiaheader.HeaderContentType))
+		assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+	})
+
+	t.Run("html response is unaffected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, &Config{JSONContentType: "application/vnd.api+json"})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, inertiaheader.ContentTypeHTML, w.Header().Get(inertiaheader.HeaderContentType))
+	})
+}
+
+func TestRenderer_SSRHeadWithTemplateHead(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	ctrl := gomock.NewController(t)
+	client := inertiassr.NewMockSSRClient(ctrl)
+	client.EXPECT().Render(gomock.Any(), gomock.Any()).Return(&SsrTemplateData{
+		Head: `<title>SSR</title><style data-ssr>.a{}</style>`,
+		Body: `<div id="app">SSR</div>`,
+	}, nil)
+
+	tpl := template.Must(template.New("test").Funcs(template.FuncMap{
+		"viteResource": func(path string) template.HTML {
+			return template.HTML(`<link rel="stylesheet" href="/` + path + `" />`) //nolint:gosec
+		},
+	}).Parse(`<head>{{ viteResource "app.css" }}{{ .InertiaHead }}</head><body>{{ .InertiaBody }}</body>`))
+	renderer := New(tpl, &Config{SSRClient: client})
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, `<head><link rel="stylesheet" href="/app.css" />`+
+		`<title>SSR</title><style data-ssr>.a{}</style></head>`+
+		`<body><div id="app">SSR</div></body>`, w.Body.String())
+}
+
+func TestRenderer_RenderTimeout(t *testing.T) {
+	t.Parallel()
+
+	slow := func(ctx context.Context) (any, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(40 * time.Millisecond):
+			return "value", nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  *Config
+		options []Option
+		wantErr bool
+	}{
+		{"config timeout", &Config{RenderTimeout: 50 * time.Millisecond}, nil, true},
+		{"option timeout", nil, []Option{WithRenderTimeout(50 * time.Millisecond)}, true},
+		{"option disables timeout", &Config{RenderTimeout: 50 * time.Millisecond}, []Option{WithRenderTimeout(-1)}, false},
+		{"within budget", &Config{RenderTimeout: time.Second}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(testTpl, tt.config)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+				Inertia:          true,
+				PartialComponent: "TestComponent",
+				Whitelist:        []string{"a", "b", "c"},
+			})
+			options := append([]Option{WithProps(Props{
+				NewOptional("a", LazyFunc(slow)),
+				NewOptional("b", LazyFunc(slow)),
+				NewOptional("c", LazyFunc(slow)),
+			})}, tt.options...)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(options...))
+
+			// assert
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrRenderTimeout)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Contains(t, err.Error(), "after 50ms")
+		})
+	}
+}
+
+func TestRenderer_SSROnlyProps(t *testing.T) {
+	t.Parallel()
+
+	props := Props{
+		NewProp("title", "Home", nil),
+		NewProp("meta", "description", &PropOptions{SSROnly: true}),
+	}
+
+	t.Run("SSR page includes the prop, hydration page doesn't", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		ctrl := gomock.NewController(t)
+		client := inertiassr.NewMockSSRClient(ctrl)
+
+		var ssrPage *Page
+
+		client.EXPECT().Render(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, p *Page) (*SsrTemplateData, error) {
+				ssrPage = p
+
+				pageJSON, err := json.Marshal(p)
+				if err != nil {
+					return nil, err
+				}
+
+				return &SsrTemplateData{
+					Head: `<meta name="description">`,
+					Body: `<div id="app" data-page="` + html.EscapeString(string(pageJSON)) + `">SSR</div>`,
+				}, nil
+			},
+		)
+
+		tpl := template.Must(template.New("test").Parse(`{{ .InertiaHead }}{{ .InertiaBody }}`))
+		renderer := New(tpl, &Config{SSRClient: client})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, ssrPage)
+		assert.Equal(t, "description", ssrPage.Props["meta"])
+		assert.Equal(t, "Home", ssrPage.Props["title"])
+
+		body := w.Body.String()
+		start := strings.Index(body, `data-page="`) + len(`data-page="`)
+		end := start + strings.IndexByte(body[start:], '"')
+
+		var page Page
+		require.NoError(t, json.Unmarshal([]byte(html.UnescapeString(body[start:end])), &page))
+		assert.NotContains(t, page.Props, "meta")
+		assert.Equal(t, "Home", page.Props["title"])
+		assert.True(t, strings.HasSuffix(body, `">SSR</div>`))
+	})
+
+	t.Run("Inertia response omits the prop", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		ctrl := gomock.NewController(t)
+		client := inertiassr.NewMockSSRClient(ctrl) // must not be called
+
+		tpl := template.Must(template.New("test").Parse(`{{ .InertiaHead }}{{ .InertiaBody }}`))
+		renderer := New(tpl, &Config{SSRClient: client})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+
+		var page Page
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.NotContains(t, page.Props, "meta")
+		assert.Equal(t, "Home", page.Props["title"])
+	})
+
+	t.Run("client-side rendered page omits the prop", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(testTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.NoError(t, err)
+		assert.NotContains(t, w.Body.String(), "description")
+	})
+}
+
+func TestRenderer_TestSSRClients(t *testing.T) {
+	t.Parallel()
+
+	tpl := template.Must(template.New("test").Parse(`<head>{{ .InertiaHead }}</head><body>{{ .InertiaBody }}</body>`))
+
+	tests := []struct {
+		name   string
+		client SSRClient
+		want   string
+	}{
+		{
+			name:   "static",
+			client: NewStaticSSRClient(`<title>Static</title>`, `<div id="app">Static</div>`),
+			want:   `<head><title>Static</title></head><body><div id="app">Static</div></body>`,
+		},
+		{
+			name: "func",
+			client: NewFuncSSRClient(func(p *Page) (SsrTemplateData, error) {
+				return SsrTemplateData{
+					Head: `<title>` + p.Component + `</title>`,
+					Body: fmt.Sprintf(`<div id="app">%v</div>`, p.Props["name"]),
+				}, nil
+			}),
+			want: `<head><title>TestComponent</title></head><body><div id="app">Jane</div></body>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(tpl, &Config{SSRClient: tt.client})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(
+				WithProps(NewProp("name", "Jane", nil)),
+			))
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+
+	t.Run("func error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		errSSR := errors.New("render failed")
+		renderer := New(tpl, &Config{SSRClient: NewFuncSSRClient(func(*Page) (SsrTemplateData, error) {
+			return SsrTemplateData{}, errSSR
+		})})
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "TestComponent", NewRenderContext())
+
+		// assert
+		require.ErrorIs(t, err, errSSR)
+		require.ErrorIs(t, err, ErrSSR)
+	})
+}
+
+func TestRenderer_SSRComponentFilter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	ctrl := gomock.NewController(t)
+	client := inertiassr.NewMockSSRClient(ctrl)
+	client.EXPECT().Render(gomock.Any(), gomock.Any()).Return(&SsrTemplateData{
+		Head: "", Body: `<div id="app">SSR</div>`,
+	}, nil).Times(1)
+
+	tpl := template.Must(template.New("test").Parse(`{{ .InertiaHead }}{{ .InertiaBody }}`))
+	renderer := New(tpl, &Config{
+		SSRClient:          client,
+		SSRComponentFilter: func(component string) bool { return component == "Home" },
+	})
+
+	// act
+	req, ssrW := inertiatest.NewRequest(http.MethodGet, "/", nil)
+	ssrErr := renderer.Render(ssrW, req, "Home", NewRenderContext())
+
+	req, csrW := inertiatest.NewRequest(http.MethodGet, "/", nil)
+	csrErr := renderer.Render(csrW, req, "Dashboard", NewRenderContext())
+
+	// assert
+	require.NoError(t, ssrErr)
+	assert.Equal(t, `<div id="app">SSR</div>`, ssrW.Body.String())
+
+	require.NoError(t, csrErr)
+	assert.Contains(t, csrW.Body.String(), `<div id="app" data-page="`)
+	assert.Contains(t, csrW.Body.String(), `&#34;component&#34;:&#34;Dashboard&#34;`)
+}
+
+func TestValidateComponentName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"Users/Index", false},
+		{"Dashboard", false},
+		{"Users.Index", false},
+		{"", true},
+		{"Pages/Users/Index.jsx", true},
+		{"Users/Show.vue", true},
+		{"./Users/Index", true},
+		{"/Users/Index", true},
+		{`Users\Index`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateComponentName(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRenderer_ComponentNameValidator(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, &Config{ComponentNameValidator: ValidateComponentName})
+
+	t.Run("valid name renders", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "Users/Index", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid name fails", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		// act
+		err := renderer.Render(w, req, "Pages/Users/Index.jsx", NewRenderContext())
+
+		// assert
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `"Pages/Users/Index.jsx"`)
+		assert.ErrorContains(t, err, `".jsx"`)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestRenderer_KnownComponents(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, &Config{KnownComponents: []string{"Users/Index", "Dashboard"}})
+
+	t.Run("present component renders", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "Dashboard", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("absent component fails", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", nil)
+
+		// act
+		err := renderer.Render(w, req, "Users/Show", NewRenderContext())
+
+		// assert
+		require.ErrorIs(t, err, ErrUnknownComponent)
+		assert.ErrorContains(t, err, `"Users/Show"`)
+	})
+}
+
+func TestComponentsFromFS(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	fsys := fstest.MapFS{
+		"Pages/Dashboard.tsx":        &fstest.MapFile{},
+		"Pages/Users/Index.tsx":      &fstest.MapFile{},
+		"Pages/Users/Show.vue":       &fstest.MapFile{},
+		"Pages/Users/styles.css":     &fstest.MapFile{},
+		"Components/Button.tsx":      &fstest.MapFile{},
+		"Pages/Users/Index.test.txt": &fstest.MapFile{},
+	}
+
+	// act
+	names, err := ComponentsFromFS(fsys, "Pages")
+
+	// assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Dashboard", "Users/Index", "Users/Show"}, names)
+
+	_, err = ComponentsFromFS(fsys, "Missing")
+	require.Error(t, err)
+}
+
+func TestRenderer_RenderJSON(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	ctrl := gomock.NewController(t)
+	client := inertiassr.NewMockSSRClient(ctrl) // must not be called
+
+	basicTpl := template.Must(template.New("test").Parse(`<html>{{.InertiaHead}}{{.InertiaBody}}</html>`))
+	renderer := New(basicTpl, &Config{Version: "1.0.0", SSRClient: client})
+	req, w := inertiatest.NewRequest(http.MethodGet, "/users", nil)
+
+	// act
+	err := renderer.RenderJSON(w, req, "Users/Index", NewRenderContext(WithProps(NewProp("count", 2, nil))))
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+	assert.Equal(t, inertiaheader.ContentTypeJSON, w.Header().Get(inertiaheader.HeaderContentType))
+
+	var page Page
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "Users/Index", page.Component)
+	assert.Equal(t, "/users", page.URL)
+	assert.Equal(t, "1.0.0", page.Version)
+	assert.InDelta(t, 2.0, page.Props["count"], 0)
+}
+
+func TestPartialProps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns requested props", func(t *testing.T) {
+		t.Parallel()
+
+		req, _ := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "Users/Index",
+			Whitelist:        []string{"stats", "users"},
+		})
+
+		assert.Equal(t, []string{"stats", "users"}, PartialProps(req, "Users/Index"))
+		assert.Nil(t, PartialProps(req, "Dashboard"))
+	})
+
+	t.Run("returns nil on full render", func(t *testing.T) {
+		t.Parallel()
+
+		req, _ := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		assert.Nil(t, PartialProps(req, "Users/Index"))
+	})
+}
+
+func TestRenderer_DynamicPartialProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, nil)
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+		Inertia:          true,
+		PartialComponent: "Users/Index",
+		Whitelist:        []string{"stats"},
+	})
+
+	// The initial page declared only "users", the partial reload supplies "stats" on demand.
+	props := Props{NewProp("users", []string{"alice"}, nil)}
+	if slices.Contains(PartialProps(req, "Users/Index"), "stats") {
+		props = append(props, NewOptional("stats", LazyFunc(func(context.Context) (any, error) {
+			return "val-stats", nil
+		})))
+	}
+
+	// act
+	err := renderer.Render(w, req, "Users/Index", NewRenderContext(WithProps(props)))
+
+	// assert
+	require.NoError(t, err)
+
+	var page Page
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "val-stats", page.Props["stats"])
+	assert.NotContains(t, page.Props, "users")
+}
+
+func TestRenderer_OptionalResolveOnFirstLoad(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, nil)
+	lazy := func(v string) Lazy {
+		return LazyFunc(func(context.Context) (any, error) { return v, nil })
+	}
+	props := Props{
+		NewProp("a", "val-a", nil),
+		NewOptionalWithOptions("eager", lazy("val-eager"), &OptionalOptions{ResolveOnFirstLoad: true}),
+		NewOptional("lazy", lazy("val-lazy")),
+		NewDeferred("deferred", lazy("val-deferred"), nil),
+	}
+
+	render := func(t *testing.T, config *inertiatest.RequestConfig) Page {
+		t.Helper()
+
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", config)
+		require.NoError(t, renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props))))
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		return page
+	}
+
+	t.Run("included on first load", func(t *testing.T) {
+		t.Parallel()
+
+		page := render(t, &inertiatest.RequestConfig{Inertia: true})
+
+		assert.Equal(t, "val-a", page.Props["a"])
+		assert.Equal(t, "val-eager", page.Props["eager"])
+		assert.NotContains(t, page.Props, "lazy")
+		assert.NotContains(t, page.Props, "deferred")
+	})
+
+	t.Run("skipped on partial reload not requesting it", func(t *testing.T) {
+		t.Parallel()
+
+		page := render(t, &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "TestComponent",
+			Whitelist:        []string{"a"},
+		})
+
+		assert.Equal(t, "val-a", page.Props["a"])
+		assert.NotContains(t, page.Props, "eager")
+	})
+
+	t.Run("resolved on partial reload requesting it", func(t *testing.T) {
+		t.Parallel()
+
+		page := render(t, &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "TestComponent",
+			Whitelist:        []string{"eager"},
+		})
+
+		assert.Equal(t, "val-eager", page.Props["eager"])
+		assert.NotContains(t, page.Props, "a")
+	})
+}
+
+func TestRenderer_EmptyPage(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, &Config{Version: "1.0.0"})
+
+	render := func(t *testing.T, renderCtx RenderContext) map[string]any {
+		t.Helper()
+
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		require.NoError(t, renderer.Render(w, req, "TestComponent", renderCtx))
+
+		var page map[string]any
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		return page
+	}
+
+	t.Run("matches the full pipeline output", func(t *testing.T) {
+		t.Parallel()
+
+		// Empty validation errors bypass the fast path, but render the same page.
+		empty := render(t, NewRenderContext(WithProps(PropIf(false, NewProp("a", 1, nil)))))
+		full := render(t, NewRenderContext(WithValidationErrors(ValidationErrors{}, DefaultErrorBag)))
+
+		assert.Equal(t, map[string]any{"errors": map[string]any{}}, empty["props"])
+		assert.Equal(t, full, empty)
+	})
+
+	t.Run("matches the full pipeline output with error bag", func(t *testing.T) {
+		t.Parallel()
+
+		emptyCtx := NewRenderContext()
+		emptyCtx.ErrorBag = "login"
+
+		empty := render(t, emptyCtx)
+		full := render(t, NewRenderContext(WithValidationErrors(ValidationErrors{}, "login")))
+
+		assert.Equal(t, map[string]any{"login": map[string]any{"errors": map[string]any{}}}, empty["props"])
+		assert.Equal(t, full, empty)
+	})
+
+	t.Run("pages do not share errors", func(t *testing.T) {
+		t.Parallel()
+
+		req, _ := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+		first, _, err := renderer.newPage(req, "TestComponent", NewRenderContext(), false)
+		require.NoError(t, err)
+
+		errs, ok := first.Props["errors"].(map[string]string)
+		require.True(t, ok)
+
+		errs["name"] = "modified"
+
+		second, _, err := renderer.newPage(req, "TestComponent", NewRenderContext(), false)
+		require.NoError(t, err)
+		assert.Empty(t, second.Props["errors"])
+	})
+}
+
+func BenchmarkRenderer_NewPage(b *testing.B) {
+	renderer := New(template.Must(template.New("test").Parse(`{{.InertiaBody}}`)), nil)
+	req, _ := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+	b.Run("zero props", func(b *testing.B) {
+		renderCtx := NewRenderContext()
+
+		b.ReportAllocs()
+
+		for b.Loop() {
+			_, _, _ = renderer.newPage(req, "TestComponent", renderCtx, false)
+		}
+	})
+
+	b.Run("zero props full pipeline", func(b *testing.B) {
+		// Empty validation errors force the full pipeline for comparison.
+		renderCtx := NewRenderContext(WithValidationErrors(ValidationErrors{}, DefaultErrorBag))
+
+		b.ReportAllocs()
+
+		for b.Loop() {
+			_, _, _ = renderer.newPage(req, "TestComponent", renderCtx, false)
+		}
+	})
+}
+
+func TestRenderer_OmitEmptyErrors(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	render := func(t *testing.T, config *Config, opts ...Option) map[string]any {
+		t.Helper()
+
+		renderer := New(basicTpl, config)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		require.NoError(t, renderer.Render(w, req, "TestComponent", NewRenderContext(opts...)))
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+		return page.Props
+	}
+
+	t.Run("empty errors are present by default", func(t *testing.T) {
+		t.Parallel()
+
+		props := render(t, nil, WithProps(NewProp("a", 1, nil)))
+
+		assert.Equal(t, map[string]any{}, props["errors"])
+	})
+
+	t.Run("empty errors are omitted", func(t *testing.T) {
+		t.Parallel()
+
+		props := render(t, &Config{OmitEmptyErrors: true}, WithProps(NewProp("a", 1, nil)))
+
+		assert.NotContains(t, props, "errors")
+		assert.Contains(t, props, "a")
+	})
+
+	t.Run("empty errors are omitted without props", func(t *testing.T) {
+		t.Parallel()
+
+		props := render(t, &Config{OmitEmptyErrors: true})
+
+		assert.Empty(t, props)
+	})
+
+	t.Run("empty errors in error bag are omitted", func(t *testing.T) {
+		t.Parallel()
+
+		props := render(t, &Config{OmitEmptyErrors: true}, WithValidationErrors(ValidationErrors{}, "login"))
+
+		assert.NotContains(t, props, "login")
+	})
+
+	t.Run("non-empty errors are present", func(t *testing.T) {
+		t.Parallel()
+
+		props := render(t, &Config{OmitEmptyErrors: true}, WithValidationErrors(ValidationErrors{
+			NewValidationError("name", "Name is required"),
+		}, DefaultErrorBag))
+
+		assert.Equal(t, map[string]any{"name": "Name is required"}, props["errors"])
+	})
+}
+
+func TestRenderer_DeferredGroups(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var statsResolved, feedResolved atomic.Int64
+
+	renderer := New(testTpl, nil)
+	props := Props{
+		NewDeferred("stats", LazyFunc(func(context.Context) (any, error) {
+			statsResolved.Add(1)
+			return 42, nil
+		}), &DeferredOptions{Group: "sidebar"}),
+		NewDeferred("feed", LazyFunc(func(context.Context) (any, error) {
+			feedResolved.Add(1)
+			return []string{"post"}, nil
+		}), &DeferredOptions{Group: "content"}),
+	}
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+		Inertia:          true,
+		PartialComponent: "TestComponent",
+		Whitelist:        []string{"stats"},
+	})
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(props)))
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), statsResolved.Load())
+	assert.Equal(t, int64(0), feedResolved.Load(), "props of other groups must not be resolved")
+
+	var page Page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, float64(42), page.Props["stats"])
+	assert.NotContains(t, page.Props, "feed")
+}
+
+func TestRenderer_UnmatchedPartialPropsWarning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		whitelist []string
+		wantLog   bool
+	}{
+		{"mismatched group", []string{"sidebar"}, true},
+		{"declared prop", []string{"stats"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var buf bytes.Buffer
+
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			renderer := New(testTpl, &Config{Logger: logger})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+				Inertia:          true,
+				PartialComponent: "TestComponent",
+				Whitelist:        tt.whitelist,
+			})
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(
+				WithDeferred("stats", LazyValue(42), nil),
+			))
+
+			// assert
+			require.NoError(t, err)
+
+			if !tt.wantLog {
+				assert.Empty(t, buf.String())
+				return
+			}
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, "DEBUG", record["level"])
+			assert.Equal(t, "inertia: partial reload requested props matching no declared prop", record["msg"])
+			assert.Equal(t, []any{"sidebar"}, record["props"])
+			assert.Equal(t, []any{DefaultDeferredGroup}, record["deferred_groups"])
+		})
+	}
+}
+
+func TestRenderer_DuplicateDeferredProps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	renderer := New(testTpl, &Config{Logger: logger})
+	req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", NewRenderContext(
+		WithDeferred("stats", LazyValue(1), &DeferredOptions{Group: "sidebar"}),
+		WithDeferred("feed", LazyValue(2), &DeferredOptions{Group: "content"}),
+		WithDeferred("stats", LazyValue(3), &DeferredOptions{Group: "content"}),
+	))
+
+	// assert
+	require.NoError(t, err)
+
+	var page Page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, map[string][]string{
+		"sidebar": {"stats"},
+		"content": {"feed"},
+	}, page.DeferredProps)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "inertia: duplicate deferred prop", record["msg"])
+	assert.Equal(t, "stats", record["prop"])
+	assert.Equal(t, "sidebar", record["group"])
+	assert.Equal(t, "content", record["duplicate_group"])
+}
+
+func TestRenderer_DeferredDependsOn(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	newProps := func(calls *atomic.Int64) Props {
+		return Props{
+			NewDeferred("user", LazyFunc(func(context.Context) (any, error) {
+				calls.Add(1)
+				return "alice", nil
+			}), &DeferredOptions{Group: "user"}),
+			NewDeferred("greeting", LazyFunc(func(ctx context.Context) (any, error) {
+				user, ok := DeferredResult(ctx, "user")
+				if !ok {
+					return nil, errors.New("user is not resolved")
+				}
+
+				return "hello " + user.(string), nil
+			}), &DeferredOptions{Group: "greeting", DependsOn: []string{"user"}}),
+		}
+	}
+
+	t.Run("dependency is resolved first and not sent", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var calls atomic.Int64
+
+		renderer := New(basicTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "Dashboard",
+			Whitelist:        []string{"greeting"},
+		})
+
+		// act
+		err := renderer.Render(w, req, "Dashboard", NewRenderContext(WithProps(newProps(&calls))))
+
+		// assert
+		require.NoError(t, err)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "hello alice", page.Props["greeting"])
+		assert.NotContains(t, page.Props, "user")
+		assert.Equal(t, int64(1), calls.Load())
+	})
+
+	t.Run("requested dependency is resolved once", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var calls atomic.Int64
+
+		renderer := New(basicTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{
+			Inertia:          true,
+			PartialComponent: "Dashboard",
+			Whitelist:        []string{"greeting", "user"},
+		})
+
+		// act
+		err := renderer.Render(w, req, "Dashboard", NewRenderContext(WithProps(newProps(&calls))))
+
+		// assert
+		require.NoError(t, err)
+
+		var page Page
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, "hello alice", page.Props["greeting"])
+		assert.Equal(t, "alice", page.Props["user"])
+		assert.Equal(t, int64(1), calls.Load())
+	})
+
+	t.Run("cycle fails the render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		value := LazyFunc(func(context.Context) (any, error) { return nil, nil })
+		props := Props{
+			NewDeferred("a", value, &DeferredOptions{Group: "a", DependsOn: []string{"b"}}),
+			NewDeferred("b", value, &DeferredOptions{Group: "b", DependsOn: []string{"c"}}),
+			NewDeferred("c", value, &DeferredOptions{Group: "c", DependsOn: []string{"a"}}),
+		}
+
+		// act
+		err := renderer.Render(w, req, "Dashboard", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.ErrorIs(t, err, ErrDeferredCycle)
+		assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	})
+
+	t.Run("unknown dependency fails the render", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(basicTpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		value := LazyFunc(func(context.Context) (any, error) { return nil, nil })
+		props := Props{NewDeferred("a", value, &DeferredOptions{Group: "a", DependsOn: []string{"missing"}})}
+
+		// act
+		err := renderer.Render(w, req, "Dashboard", NewRenderContext(WithProps(props)))
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown group missing")
+	})
+}
+
+func TestRenderer_StrictPartialHeaders(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	props := Props{
+		NewProp("users", []string{"alice"}, &PropOptions{Merge: true}),
+		NewProp("stats", 1, nil),
+	}
+
+	tests := []struct {
+		name      string
+		reqConfig *inertiatest.RequestConfig
+		strict    bool
+		wantErr   bool
+	}{
+		{
+			name: "reset header naming a nonexistent prop",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Users", Whitelist: []string{"users"}, ResetProps: []string{"user"},
+			},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name: "partial data naming a nonexistent prop",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Users", Whitelist: []string{"users", "stat"},
+			},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name: "declared props",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Users", Whitelist: []string{"users", "errors"}, ResetProps: []string{"users"},
+			},
+			strict: true,
+		},
+		{
+			name: "other component",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Other", Whitelist: []string{"user"},
+			},
+			strict: true,
+		},
+		{
+			name: "not strict",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Users", Whitelist: []string{"users"}, ResetProps: []string{"user"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(basicTpl, &Config{StrictPartialHeaders: tt.strict})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", tt.reqConfig)
+
+			// act
+			err := renderer.Render(w, req, "Users", NewRenderContext(WithProps(props)))
+
+			// assert
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnknownPartialProp)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRenderer_ErrorCategories(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaHead}}{{.InertiaBody}}`))
+	errBoom := errors.New("boom")
+	failing := NewOptionalWithOptions("failing", LazyFunc(func(context.Context) (any, error) {
+		return nil, errBoom
+	}), &OptionalOptions{ResolveOnFirstLoad: true})
+
+	tests := []struct {
+		name     string
+		tpl      *template.Template
+		ssr      bool
+		inertia  bool
+		props    Props
+		category error
+	}{
+		{name: "prop resolution", tpl: basicTpl, props: Props{failing}, category: ErrPropResolution},
+		{name: "SSR", tpl: basicTpl, ssr: true, category: ErrSSR},
+		{
+			name:     "template",
+			tpl:      template.Must(template.New("test").Parse(`{{.InertiaBody}}{{.Missing}}`)),
+			category: ErrTemplate,
+		},
+		{
+			name:     "JSON encoding",
+			tpl:      basicTpl,
+			inertia:  true,
+			props:    Props{NewProp("ch", make(chan int), nil)},
+			category: ErrJSONEncode,
+		},
+		{
+			name:     "JSON encoding of the root view",
+			tpl:      basicTpl,
+			props:    Props{NewProp("ch", make(chan int), nil)},
+			category: ErrJSONEncode,
+		},
+	}
+
+	categories := []error{ErrPropResolution, ErrSSR, ErrTemplate, ErrJSONEncode}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			config := &Config{}
+
+			if tt.ssr {
+				ctrl := gomock.NewController(t)
+				client := inertiassr.NewMockSSRClient(ctrl)
+				client.EXPECT().Render(gomock.Any(), gomock.Any()).Return(nil, errBoom)
+				config.SSRClient = client
+			}
+
+			renderer := New(tt.tpl, config)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: tt.inertia})
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(tt.props)))
+
+			// assert
+			require.ErrorIs(t, err, tt.category)
+			assert.True(t, strings.HasPrefix(err.Error(), "inertia: failed to"), err.Error())
+
+			for _, category := range categories {
+				if category != tt.category {
+					assert.NotErrorIs(t, err, category)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_MergeOnPartialOnly(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	lazy := LazyValue([]string{"c"})
+	props := Props{
+		NewProp("posts", []string{"a"}, &PropOptions{Merge: true, MergeOnPartialOnly: true}),
+		NewProp("tags", []string{"b"}, &PropOptions{Merge: true}),
+		NewDeferred("comments", lazy, &DeferredOptions{Merge: true, MergeOnPartialOnly: true}),
+	}
+
+	tests := []struct {
+		name      string
+		reqConfig *inertiatest.RequestConfig
+		want      []string
+	}{
+		{
+			name:      "full load",
+			reqConfig: &inertiatest.RequestConfig{Inertia: true},
+			want:      []string{"tags"},
+		},
+		{
+			name: "partial reload",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Posts", Whitelist: []string{"posts", "tags", "comments"},
+			},
+			want: []string{"posts", "tags", "comments"},
+		},
+		{
+			name: "partial reload of another component",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "Other", Whitelist: []string{"posts"},
+			},
+			want: []string{"tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(basicTpl, nil)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", tt.reqConfig)
+
+			// act
+			err := renderer.Render(w, req, "Posts", NewRenderContext(WithProps(props)))
+
+			// assert
+			require.NoError(t, err)
+
+			var page Page
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			assert.Equal(t, tt.want, page.MergeProps)
+		})
+	}
+}
+
+func TestRenderer_RegisterDefaults(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	tests := []struct {
+		name      string
+		component string
+		props     Props
+		want      map[string]any
+	}{
+		{
+			name:      "registered default is present",
+			component: "Dashboard",
+			props:     Props{NewProp("title", "Home", nil)},
+			want:      map[string]any{"title": "Home", "nav": "default", "user": "jane"},
+		},
+		{
+			name:      "page prop overrides registered default",
+			component: "Dashboard",
+			props:     Props{NewProp("nav", "page", nil)},
+			want:      map[string]any{"nav": "page", "user": "jane"},
+		},
+		{
+			name:      "other component",
+			component: "Settings",
+			props:     Props{NewProp("title", "Settings", nil)},
+			want:      map[string]any{"title": "Settings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(basicTpl, nil)
+			renderer.RegisterDefaults("Dashboard", Props{NewProp("nav", "default", nil)})
+			renderer.RegisterDefaults("Dashboard", Props{NewProp("user", "jane", nil)})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+			// act
+			err := renderer.Render(w, req, tt.component, NewRenderContext(WithProps(tt.props)))
+
+			// assert
+			require.NoError(t, err)
+
+			var page struct {
+				Props map[string]any `json:"props"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			delete(page.Props, "errors")
+			assert.Equal(t, tt.want, page.Props)
+		})
+	}
+}
+
+func TestRenderer_StatusCode(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+
+	tests := []struct {
+		name      string
+		reqConfig *inertiatest.RequestConfig
+		options   []Option
+		expected  int
+	}{
+		{"default JSON", &inertiatest.RequestConfig{Inertia: true}, nil, http.StatusOK},
+		{"default HTML", nil, nil, http.StatusOK},
+		{"JSON", &inertiatest.RequestConfig{Inertia: true}, []Option{WithStatus(http.StatusUnprocessableEntity)}, http.StatusUnprocessableEntity},
+		{"HTML", nil, []Option{WithStatus(http.StatusCreated)}, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(basicTpl, nil)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", tt.reqConfig)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(tt.options...))
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+//nolint:paralleltest // Modifies GOMAXPROCS.
+func TestDefaultConcurrency(t *testing.T) {
+	// arrange
+	prev := runtime.GOMAXPROCS(0)
+	t.Cleanup(func() { runtime.GOMAXPROCS(prev) })
+
+	runtime.GOMAXPROCS(prev + 1)
+
+	// act
+	renderer := New(testTpl, nil)
+
+	// assert
+	assert.Equal(t, prev+1, DefaultConcurrency())
+	assert.Equal(t, prev+1, renderer.concurrency)
+}
+
+func TestRenderer_PropOptions(t *testing.T) {
+	t.Parallel()
+
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	options := []Option{
+		WithOptional("optional", LazyValue("lazy")),
+		WithDeferred("deferred", LazyValue("later"), &DeferredOptions{Group: "stats"}),
+		WithAlways("always", "value"),
+	}
+
+	tests := []struct {
+		name         string
+		reqConfig    *inertiatest.RequestConfig
+		wantProps    map[string]any
+		wantDeferred map[string][]string
+	}{
+		{
+			name:         "full load",
+			reqConfig:    &inertiatest.RequestConfig{Inertia: true},
+			wantProps:    map[string]any{"always": "value"},
+			wantDeferred: map[string][]string{"stats": {"deferred"}},
+		},
+		{
+			name: "partial reload",
+			reqConfig: &inertiatest.RequestConfig{
+				Inertia: true, PartialComponent: "TestComponent", Whitelist: []string{"optional", "deferred"},
+			},
+			wantProps:    map[string]any{"always": "value", "optional": "lazy", "deferred": "later"},
+			wantDeferred: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(basicTpl, nil)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", tt.reqConfig)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(options...))
+
+			// assert
+			require.NoError(t, err)
+
+			var page struct {
+				Props         map[string]any      `json:"props"`
+				DeferredProps map[string][]string `json:"deferredProps"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			delete(page.Props, "errors")
+			assert.Equal(t, tt.wantProps, page.Props)
+			assert.Equal(t, tt.wantDeferred, page.DeferredProps)
+		})
+	}
+}
+
+func TestRenderer_IndentJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		indent    bool
+		reqConfig *inertiatest.RequestConfig
+	}{
+		{"JSON indented", true, &inertiatest.RequestConfig{Inertia: true}},
+		{"JSON compact", false, &inertiatest.RequestConfig{Inertia: true}},
+		{"data page indented", true, nil},
+		{"data page compact", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(testTpl, &Config{IndentJSON: tt.indent})
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", tt.reqConfig)
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(
+				WithProps(NewProp("name", "Jane", nil)),
+			))
+
+			// assert
+			require.NoError(t, err)
+
+			body := html.UnescapeString(w.Body.String())
+			if tt.indent {
+				assert.Contains(t, body, "\n  \"component\": \"TestComponent\"")
+			} else {
+				assert.Contains(t, body, `"component":"TestComponent"`)
+				assert.NotContains(t, body, "\n  \"")
+			}
+		})
+	}
+}
+
+func TestRenderer_PollInterval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    any
+	}{
+		{"interval without props", []Option{WithPollInterval(5 * time.Second)}, float64(5000)},
+		{"interval with props", []Option{
+			WithPollInterval(1500 * time.Millisecond), WithProps(NewProp("a", 1, nil)),
+		}, float64(1500)},
+		{"no interval without props", nil, nil},
+		{"no interval with props", []Option{WithProps(NewProp("a", 1, nil))}, nil},
+		{"negative interval", []Option{WithPollInterval(-time.Second)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			renderer := New(testTpl, nil)
+			req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+
+			// act
+			err := renderer.Render(w, req, "TestComponent", NewRenderContext(tt.options...))
+
+			// assert
+			require.NoError(t, err)
+
+			var page map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+
+			if tt.want == nil {
+				assert.NotContains(t, page, "pollInterval")
+				assert.NotContains(t, w.Body.String(), "pollInterval")
+
+				return
+			}
+
+			assert.Equal(t, tt.want, page["pollInterval"])
+		})
+	}
+}
+
+func TestRenderer_Head(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	basicTpl := template.Must(template.New("test").Parse(`{{.InertiaBody}}`))
+	renderer := New(basicTpl, nil)
+	req, w := inertiatest.NewRequest(http.MethodHead, "/", &inertiatest.RequestConfig{Inertia: true})
+
+	var resolved atomic.Bool
+
+	prop := NewOptionalWithOptions("stats", LazyFunc(func(context.Context) (any, error) {
+		resolved.Store(true)
+		return nil, nil
+	}), &OptionalOptions{ResolveOnFirstLoad: true})
+
+	// act
+	err := renderer.Render(w, req, "TestComponent", NewRenderContext(WithProps(Props{prop}), WithStatus(http.StatusAccepted)))
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "true", w.Header().Get(inertiaheader.HeaderXInertia))
+	assert.Equal(t, inertiaheader.ContentTypeJSON, w.Header().Get(inertiaheader.HeaderContentType))
+	assert.Empty(t, w.Body.String())
+	assert.False(t, resolved.Load())
+}
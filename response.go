@@ -8,9 +8,20 @@ import (
 
 var (
 	_ http.ResponseWriter                       = (*responseWriter)(nil)
+	_ streamFlusher                             = (*responseWriter)(nil)
 	_ interface{ Unwrap() http.ResponseWriter } = (*responseWriter)(nil)
+	_ http.Flusher                              = (*ResponseRecorder)(nil)
 )
 
+// streamFlusher is implemented by the buffered response writer to stream
+// JSON responses, see Config.StreamJSON.
+//
+// Unlike http.Flusher, it isn't exposed to handlers, as flushing commits
+// the status code before the middleware can rewrite it, e.g., 302 to 303.
+type streamFlusher interface {
+	flushStream()
+}
+
 //nolint:gochecknoglobals
 var bufPool = sync.Pool{New: func() any { return bytes.NewBuffer(nil) }}
 
@@ -20,7 +31,7 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 		statusCode:     http.StatusOK,
 		size:           0,
 		flushed:        false,
-		dirty:          false,
+		wroteHeader:    false,
 
 		//nolint:forcetypeassert
 		buf: bufPool.Get().(*bytes.Buffer),
@@ -32,20 +43,26 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 type responseWriter struct {
 	http.ResponseWriter
 
-	buf        *bytes.Buffer
-	statusCode int
-	size       int
-	flushed    bool
-	dirty      bool
+	buf         *bytes.Buffer
+	statusCode  int
+	size        int
+	flushed     bool
+	wroteHeader bool
 }
 
 func (w *responseWriter) WriteHeader(code int) {
-	w.dirty = true
+	w.wroteHeader = true
 	w.statusCode = code
 }
 
 func (w *responseWriter) Write(b []byte) (int, error) {
-	w.dirty = true
+	// Once flushed, the response is written through.
+	if w.flushed {
+		n, err := w.ResponseWriter.Write(b)
+		w.size += n
+
+		return n, err //nolint:wrapcheck
+	}
 
 	n, err := w.buf.Write(b)
 	w.size += n
@@ -58,18 +75,33 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return n, nil
 }
 
+// Empty reports whether neither a status code nor a body was written.
+//
+// Setting headers or writing a zero-length body doesn't count as a response,
+// while a status code written explicitly does, even without a body,
+// e.g., 304 Not Modified or 403 Forbidden.
 func (w *responseWriter) Empty() bool {
-	if w.dirty {
-		return false
-	}
-
-	return w.size == 0
+	return !w.wroteHeader && w.size == 0
 }
 
 func (w *responseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
 
+// FlushError reports that handlers can't flush the buffered response, so that
+// http.ResponseController doesn't flush the unwrapped http.ResponseWriter
+// either, see streamFlusher.
+func (w *responseWriter) FlushError() error { return http.ErrNotSupported }
+
+// flushStream writes the buffered response to the underlying http.ResponseWriter
+// and flushes it to the client. Subsequent writes are written through,
+// and the status code can no longer be changed.
+func (w *responseWriter) flushStream() {
+	w.flush()
+
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
 // flush writes the buffered response to the underlying http.ResponseWriter.
 func (w *responseWriter) flush() {
 	if w.flushed {
@@ -84,3 +116,42 @@ func (w *responseWriter) flush() {
 	w.buf.Reset()
 	bufPool.Put(w.buf)
 }
+
+// ResponseRecorder buffers a response written to it until Flush is called,
+// e.g., to cache a rendered Inertia response or to compare it with a golden file.
+//
+// It buffers the response the same way NewMiddleware does for Inertia requests.
+type ResponseRecorder struct {
+	*responseWriter
+}
+
+// NewResponseRecorder returns a ResponseRecorder buffering the response written to w.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{newResponseWriter(w)}
+}
+
+// Flush writes the recorded response to the underlying http.ResponseWriter
+// and flushes it to the client. Subsequent writes are written through,
+// and the status code can no longer be changed.
+func (r *ResponseRecorder) Flush() { r.flushStream() }
+
+// FlushError is like Flush, used by http.ResponseController.
+func (r *ResponseRecorder) FlushError() error {
+	r.flushStream()
+
+	return nil
+}
+
+// StatusCode returns the recorded status code, 200 OK if none was written.
+func (r *ResponseRecorder) StatusCode() int { return r.statusCode }
+
+// Body returns the recorded response body.
+//
+// The returned slice is valid until the response is flushed.
+func (r *ResponseRecorder) Body() []byte {
+	if r.flushed {
+		return nil
+	}
+
+	return r.buf.Bytes()
+}
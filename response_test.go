@@ -0,0 +1,89 @@
+package inertia
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.segfaultmedaddy.com/inertia/internal/inertiatest"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty response", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		w := httptest.NewRecorder()
+		rec := NewResponseRecorder(w)
+
+		// act
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}).ServeHTTP(rec, nil)
+
+		// assert
+		assert.True(t, rec.Empty())
+		assert.Equal(t, http.StatusOK, rec.StatusCode())
+		assert.Empty(t, rec.Body())
+	})
+
+	t.Run("status only", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rec := NewResponseRecorder(httptest.NewRecorder())
+
+		// act
+		rec.WriteHeader(http.StatusNoContent)
+
+		// assert
+		assert.False(t, rec.Empty())
+		assert.Equal(t, http.StatusNoContent, rec.StatusCode())
+	})
+
+	t.Run("headers and zero-length body only", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rec := NewResponseRecorder(httptest.NewRecorder())
+
+		// act
+		rec.Header().Set("X-Custom", "value")
+		_, err := rec.Write(nil)
+
+		// assert
+		require.NoError(t, err)
+		assert.True(t, rec.Empty())
+	})
+
+	t.Run("rendered response", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		renderer := New(tpl, nil)
+		req, w := inertiatest.NewRequest(http.MethodGet, "/", &inertiatest.RequestConfig{Inertia: true})
+		rec := NewResponseRecorder(w)
+
+		// act
+		err := renderer.Render(rec, req, "Home", NewRenderContext())
+
+		// assert
+		require.NoError(t, err)
+		assert.False(t, rec.Empty())
+		assert.Equal(t, http.StatusOK, rec.StatusCode())
+		assert.Contains(t, string(rec.Body()), `"component":"Home"`)
+		assert.Equal(t, "true", rec.Header().Get("X-Inertia"))
+		assert.Empty(t, w.Body.String(), "response should be buffered until flushed")
+
+		// act
+		body := string(rec.Body())
+		rec.Flush()
+
+		// assert
+		assert.Equal(t, body, w.Body.String())
+		assert.Nil(t, rec.Body())
+	})
+}
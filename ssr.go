@@ -1,6 +1,7 @@
 package inertia
 
 import (
+	"context"
 	"net/http"
 
 	"go.segfaultmedaddy.com/inertia/internal/inertiassr"
@@ -12,10 +13,20 @@ type (
 
 	// SsrTemplateData contains the HTML head and body sections returned by SSR rendering.
 	SsrTemplateData = inertiassr.SSRTemplateData
+
+	// SSRClientOptions configures the HTTP SSR client.
+	SSRClientOptions = inertiassr.Options
 )
 
-// NewHTTPSsrClient creates an HTTP-based SSR client that sends render requests to the specified URL.
+// ErrSSRResponseTooLarge is returned when the SSR response body exceeds
+// SSRClientOptions.MaxResponseBytes.
+var ErrSSRResponseTooLarge = inertiassr.ErrResponseTooLarge
+
+// NewHTTPSsrClient creates an HTTP-based SSR client that sends GET render requests to the specified URL.
 // If client is nil, http.DefaultClient is used.
+//
+// Use NewHTTPSsrClientWithOptions with SSRClientOptions.Method to send render requests
+// with another method, e.g., POST.
 func NewHTTPSsrClient(url string, client *http.Client) SSRClient {
 	if client == nil {
 		client = http.DefaultClient
@@ -23,3 +34,40 @@ func NewHTTPSsrClient(url string, client *http.Client) SSRClient {
 
 	return inertiassr.NewHTTPSsrClient(url, client)
 }
+
+// NewHTTPSsrClientWithOptions creates an HTTP-based SSR client configured with opts
+// that sends render requests to the specified URL.
+// If opts is nil or opts.Client is nil, http.DefaultClient is used.
+func NewHTTPSsrClientWithOptions(url string, opts *SSRClientOptions) SSRClient {
+	var o SSRClientOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+
+	return inertiassr.NewHTTPSsrClientWithOptions(url, &o)
+}
+
+// NewSSRHandler returns an HTTP handler implementing the SSR protocol used by
+// the HTTP SSR client, allowing to run the SSR service in Go.
+//
+// The handler decodes the page sent by the client, calls render and writes
+// the rendered head and body back.
+func NewSSRHandler(render func(context.Context, *Page) (*SsrTemplateData, error)) http.Handler {
+	return inertiassr.Handler(render)
+}
+
+// NewStaticSSRClient creates an SSR client rendering every page with the given head and body,
+// e.g., to test the server-side rendering path without an SSR service.
+func NewStaticSSRClient(head, body string) SSRClient {
+	return inertiassr.NewStaticSSRClient(head, body)
+}
+
+// NewFuncSSRClient creates an SSR client rendering pages with fn,
+// e.g., to test the server-side rendering path with responses depending on the page.
+func NewFuncSSRClient(fn func(*Page) (SsrTemplateData, error)) SSRClient {
+	return inertiassr.NewFuncSSRClient(fn)
+}
@@ -9,8 +9,11 @@ var (
 	_ error = (*ValidationErrors)(nil)
 
 	_ ValidationError   = (*validationError)(nil)
+	_ MessageKeyer      = (*validationError)(nil)
 	_ ValidationErrorer = (*validationError)(nil)
 	_ ValidationErrorer = (*ValidationErrors)(nil)
+	_ ValidationErrorer = (*errorBagErrorer)(nil)
+	_ ErrorBagger       = (*errorBagErrorer)(nil)
 )
 
 const (
@@ -32,6 +35,17 @@ type ValidationError interface {
 	Error() string
 }
 
+// MessageKeyer is implemented by validation errors carrying a translatable message key.
+//
+// The Renderer localizes such errors with Config.Translator at render time.
+type MessageKeyer interface {
+	// MessageKey returns the message key, empty if the error has a fixed message.
+	MessageKey() string
+
+	// MessageParams returns the parameters to interpolate into the translated message.
+	MessageParams() map[string]any
+}
+
 // ValidationErrorer is a collection of validation errors that can be sent to the client.
 type ValidationErrorer interface {
 	error
@@ -43,18 +57,46 @@ type ValidationErrorer interface {
 	Len() int
 }
 
+// ErrorBagger is implemented by validation errors scoped to a specific error bag.
+//
+// inertiaframe flashes such errors to the returned error bag instead of the one
+// requested by the client. An empty error bag means no preference.
+type ErrorBagger interface {
+	// ErrorBag returns the name of the error bag.
+	ErrorBag() string
+}
+
 type validationError struct {
-	Field_    string //nolint:revive
-	Message_  string //nolint:revive
-	ErrorBag_ string //nolint:revive
+	Params_   map[string]any //nolint:revive
+	Field_    string         //nolint:revive
+	Message_  string         //nolint:revive
+	Key_      string         //nolint:revive
+	ErrorBag_ string         //nolint:revive
 }
 
 // NewValidationError creates a validation error for a specific field with a message.
 // The error is associated with the default error bag.
 func NewValidationError(field string, message string) *validationError { //nolint:revive
 	return &validationError{
+		Params_:   nil,
 		Field_:    field,
 		Message_:  message,
+		Key_:      "",
+		ErrorBag_: DefaultErrorBag,
+	}
+}
+
+// NewValidationErrorKey creates a validation error for a specific field with
+// a message key and params, translated at render time using Config.Translator.
+//
+// If no translator is configured, the key is used as the message.
+// Params must be gob-encodable to be flashed across requests.
+func NewValidationErrorKey(field string, key string, params map[string]any) *validationError { //nolint:revive
+	return &validationError{
+		Params_:   params,
+		Field_:    field,
+		Message_:  key,
+		Key_:      key,
 		ErrorBag_: DefaultErrorBag,
 	}
 }
@@ -63,9 +105,26 @@ func (err *validationError) Error() string                       { return err.Me
 func (err *validationError) Field() string                       { return err.Field_ }
 func (err *validationError) ValidationErrors() []ValidationError { return []ValidationError{err} }
 func (err *validationError) Len() int                            { return 1 }
+func (err *validationError) MessageKey() string                  { return err.Key_ }
+func (err *validationError) MessageParams() map[string]any       { return err.Params_ }
 
 type ValidationErrors []ValidationError
 
 func (errs ValidationErrors) Error() string                       { return "validation errors" }
 func (errs ValidationErrors) ValidationErrors() []ValidationError { return errs }
 func (errs ValidationErrors) Len() int                            { return len(errs) }
+
+type errorBagErrorer struct {
+	ValidationErrorer
+
+	errorBag string
+}
+
+// InErrorBag scopes errorer to the given error bag, see ErrorBagger.
+// Useful for endpoints serving multiple forms on the same page.
+func InErrorBag(errorer ValidationErrorer, errorBag string) ValidationErrorer {
+	return &errorBagErrorer{errorer, errorBag}
+}
+
+func (err *errorBagErrorer) ErrorBag() string { return err.errorBag }
+func (err *errorBagErrorer) Unwrap() error    { return err.ValidationErrorer }
@@ -77,6 +77,39 @@ func TestValidationError(t *testing.T) {
 	})
 }
 
+func TestValidationErrorKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NewValidationErrorKey", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		params := map[string]any{"min": 8}
+
+		// act
+		err := NewValidationErrorKey("password", "validation.min", params)
+
+		// assert
+		assert.Equal(t, "password", err.Field())
+		assert.Equal(t, "validation.min", err.MessageKey())
+		assert.Equal(t, params, err.MessageParams())
+		assert.Equal(t, "validation.min", err.Error(), "key is used as the message")
+	})
+
+	t.Run("NewValidationError has no message key", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		err := NewValidationError("email", "Email is invalid")
+
+		// act
+		key := err.MessageKey()
+
+		// assert
+		assert.Empty(t, key)
+	})
+}
+
 func TestValidationErrors(t *testing.T) {
 	t.Parallel()
 
@@ -141,3 +174,25 @@ func TestValidationErrors(t *testing.T) {
 		assert.Equal(t, 0, result)
 	})
 }
+
+func TestInErrorBag(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	errs := ValidationErrors{NewValidationError("email", "Email is invalid")}
+
+	// act
+	err := InErrorBag(errs, "login")
+
+	// assert
+	bagger, ok := err.(ErrorBagger)
+	require.True(t, ok)
+	assert.Equal(t, "login", bagger.ErrorBag())
+	assert.Equal(t, errs.ValidationErrors(), err.ValidationErrors())
+	assert.Equal(t, 1, err.Len())
+
+	var unwrapped ValidationErrors
+
+	require.ErrorAs(t, err, &unwrapped)
+	assert.Equal(t, errs, unwrapped)
+}
@@ -0,0 +1,40 @@
+package inertia
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// VersionFromFile returns an asset version derived from the contents
+// of the file at path, e.g., the manifest generated by the bundler,
+// for use as Config.Version.
+//
+// The version changes whenever the file contents change.
+func VersionFromFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("inertia: failed to open version file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("inertia: failed to read version file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VersionFromEnv returns the asset version stored in the environment
+// variable key, for use as Config.Version.
+//
+// If the variable is unset, it returns an empty string, so a fallback
+// can be provided with cmp.Or:
+//
+//	version := cmp.Or(inertia.VersionFromEnv("ASSETS_VERSION"), "dev")
+func VersionFromEnv(key string) string {
+	return os.Getenv(key)
+}
@@ -0,0 +1,62 @@
+package inertia
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionFromFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("present file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		path := filepath.Join(t.TempDir(), "manifest.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"app.js":"app-1.js"}`), 0o600))
+
+		// act
+		v1, err := VersionFromFile(path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"app.js":"app-2.js"}`), 0o600))
+
+		v2, err := VersionFromFile(path)
+		require.NoError(t, err)
+
+		// assert
+		assert.Len(t, v1, 64)
+		assert.NotEqual(t, v1, v2, "version should change with the file contents")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		// act
+		v, err := VersionFromFile(filepath.Join(t.TempDir(), "missing.json"))
+
+		// assert
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.Empty(t, v)
+	})
+}
+
+//nolint:paralleltest
+func TestVersionFromEnv(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		t.Setenv("INERTIA_TEST_VERSION", "abc123")
+
+		assert.Equal(t, "abc123", VersionFromEnv("INERTIA_TEST_VERSION"))
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		t.Setenv("INERTIA_TEST_VERSION", "")
+
+		assert.Equal(t, "dev", cmp.Or(VersionFromEnv("INERTIA_TEST_VERSION"), "dev"))
+	})
+}